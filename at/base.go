@@ -1,9 +1,8 @@
 package at
 
 import (
-	"bufio"
+	"context"
 	"fmt"
-	"io"
 	"log"
 	"strconv"
 	"strings"
@@ -36,25 +35,62 @@ type Config struct {
 	ResponseSet     *ResponseSet         // 自定义响应类型集，如果为 nil 则使用默认响应集
 	NotificationSet *NotificationSet     // 自定义通知类型集，如果为 nil 则使用默认通知集
 	Printf          func(string, ...any) // 日志输出函数，如果为 nil 则使用 log.Printf
+	EventBuffer     int                  // 每个通知订阅通道的容量，默认 16
+	EventPolicy     EventPolicy          // 订阅通道已满时的处理策略，默认丢弃新事件
+	PortFactory     func() (Port, error) // 串口工厂，设置后串口失效时自动重新打开，为 nil 时不重连
+	InitScript      []string             // 初始化命令，如 ATE0、AT+CMEE=1，串口重新打开后自动重放
+	ReconnectDelay  time.Duration        // 首次重连前的等待时间，之后指数退避，默认 1 秒
+	ReconnectMax    time.Duration        // 重连等待时间上限，默认 30 秒
+	EscapeGuard     time.Duration        // 退出数据模式时 "+++" 前后的静默时间，默认 1 秒
+	EscapeDTR       bool                 // 退出数据模式时拉低 DTR 而非发送 "+++"，串口需实现 SetDTR(bool) error
+	USSDPacked      bool                 // USSD 请求以 GSM7 打包的十六进制发送（如华为模块）
+	SIMPIN          string               // SIM 卡 PIN，设置后 Init 和重连时自动解锁
+	SIMPUK          string               // SIM 卡 PUK，PIN 已锁定时用于解锁并将 PIN 重置为 SIMPIN
 }
 
 // 设备连接
 type Device struct {
-	port          Port                 // 串口连接
-	name          string               // 设备名称
-	timeout       time.Duration        // 超时时间
-	commands      CommandSet           // 使用的 AT 命令集
-	responses     ResponseSet          // 使用的响应类型集
-	responseChan  chan string          // 命令响应通道
-	notifications NotificationSet      // 使用的通知类型集
-	urcHandler    UrcHandler           // 通知处理函数
-	printf        func(string, ...any) // 日志输出函数
-	closed        atomic.Bool          // 连接是否已关闭（原子操作保证并发安全）
-	cmd           atomic.Value         // 当前正在执行的命令
-	mu            sync.Mutex           // 保护命令发送的互斥锁
-}
-
-// 通知处理函数
+	port              Port                     // 串口连接，串口失效后到重新打开前为 nil
+	portMu            sync.RWMutex             // 保护 port
+	portFactory       func() (Port, error)     // 串口工厂
+	initScript        []string                 // 初始化命令
+	reconnectDelay    time.Duration            // 首次重连等待时间
+	reconnectMaxDelay time.Duration            // 重连等待时间上限
+	state             atomic.Int32             // 连接状态
+	done              chan struct{}            // 设备关闭时关闭
+	name              string                   // 设备名称
+	timeout           time.Duration            // 超时时间
+	commands          CommandSet               // 使用的 AT 命令集
+	responses         ResponseSet              // 使用的响应类型集
+	responseChan      chan string              // 命令响应通道
+	notifications     NotificationSet          // 使用的通知类型集
+	subs              []*subscriber            // 通知订阅者
+	subMu             sync.Mutex               // 保护订阅者列表
+	eventBuffer       int                      // 订阅通道容量
+	eventPolicy       EventPolicy              // 订阅通道满时的处理策略
+	printf            func(string, ...any)     // 日志输出函数
+	closed            atomic.Bool              // 连接是否已关闭（原子操作保证并发安全）
+	cmd               atomic.Value             // 当前正在执行的命令
+	echoLine          atomic.Value             // 等待剥离的命令回显
+	echo              atomic.Int32             // 回显状态 0 未知 1 关闭 2 开启
+	charset           atomic.Value             // 当前 TE 字符集
+	smsMode           atomic.Int32             // 当前短信模式 0 PDU 1 TEXT
+	reports           map[int]*SentSMS         // 等待状态报告的短信，按消息参考号索引
+	reportMu          sync.Mutex               // 保护 reports
+	reportOnce        sync.Once                // 状态报告跟踪只启动一次
+	lock              chan struct{}            // 保护命令发送的锁（可被 context 取消等待）
+	data              atomic.Pointer[DataConn] // 数据模式连接，命令模式下为 nil
+	escapeGuard       time.Duration            // "+++" 前后的静默时间
+	escapeDTR         bool                     // 通过 DTR 退出数据模式
+	ussdPacked        bool                     // USSD 请求以 GSM7 打包发送
+	simPIN            string                   // 自动解锁使用的 PIN
+	simPUK            string                   // 自动解锁使用的 PUK
+	pinRejected       atomic.Bool              // 自动解锁的密码已被拒绝
+	call              CallEvent                // 当前通话状态
+	callMu            sync.Mutex               // 保护 call
+}
+
+// 通知处理函数，按通知到达顺序依次调用
 type UrcHandler func(string, map[int]string)
 
 // New 创建一个新的设备连接实例
@@ -77,16 +113,48 @@ func New(port Port, handler UrcHandler, config *Config) *Device {
 	if config.Printf == nil {
 		config.Printf = log.Printf
 	}
+	if config.EventBuffer <= 0 {
+		config.EventBuffer = 16
+	}
+	if config.ReconnectDelay <= 0 {
+		config.ReconnectDelay = time.Second
+	}
+	if config.ReconnectMax < config.ReconnectDelay {
+		config.ReconnectMax = max(30*time.Second, config.ReconnectDelay)
+	}
+	if config.EscapeGuard <= 0 {
+		config.EscapeGuard = time.Second
+	}
 
 	dev := &Device{
-		port:          port,
-		timeout:       config.Timeout,
-		commands:      *config.CommandSet,
-		responses:     *config.ResponseSet,
-		responseChan:  make(chan string, 100),
-		notifications: *config.NotificationSet,
-		urcHandler:    handler,
-		printf:        config.Printf,
+		port:              port,
+		portFactory:       config.PortFactory,
+		initScript:        config.InitScript,
+		reconnectDelay:    config.ReconnectDelay,
+		reconnectMaxDelay: config.ReconnectMax,
+		done:              make(chan struct{}),
+		timeout:           config.Timeout,
+		commands:          *config.CommandSet,
+		responses:         *config.ResponseSet,
+		responseChan:      make(chan string, 100),
+		notifications:     *config.NotificationSet,
+		eventBuffer:       config.EventBuffer,
+		eventPolicy:       config.EventPolicy,
+		reports:           map[int]*SentSMS{},
+		printf:            config.Printf,
+		lock:              make(chan struct{}, 1),
+		escapeGuard:       config.EscapeGuard,
+		escapeDTR:         config.EscapeDTR,
+		ussdPacked:        config.USSDPacked,
+		simPIN:            config.SIMPIN,
+		simPUK:            config.SIMPUK,
+	}
+	dev.cmd.Store("")
+	dev.echoLine.Store("")
+
+	// 兼容通知处理函数，通过订阅全部通知按顺序回调
+	if handler != nil {
+		dev.handleLegacy(handler)
 	}
 
 	// 开始读取循环
@@ -107,21 +175,112 @@ func (m *Device) Close() error {
 		return nil // 已经关闭过了
 	}
 
-	close(m.responseChan)
+	close(m.done)
+	m.closeSubscribers()
 
-	return m.port.Close()
+	// 重连过程中串口可能为 nil
+	m.portMu.Lock()
+	port := m.port
+	m.port = nil
+	m.portMu.Unlock()
+	if port == nil {
+		return nil
+	}
+	return port.Close()
 }
 
 // SendCommand 发送命令并等待响应
 func (m *Device) SendCommand(cmd string) ([]string, error) {
+	return m.SendCommandContext(context.Background(), cmd)
+}
+
+// SendCommandContext 发送命令并等待响应，支持通过 ctx 取消或设置截止时间
+//
+// 超时时间取 CommandSet.Timeouts 中匹配的命令超时（未匹配时为 Config.Timeout），
+// ctx 带有更早的截止时间时以 ctx 为准。
+func (m *Device) SendCommandContext(ctx context.Context, cmd string) ([]string, error) {
+	return m.sendCommand(ctx, cmd, m.commandTimeout(cmd))
+}
+
+// sendCommand 发送命令并在指定超时内等待响应
+func (m *Device) sendCommand(ctx context.Context, cmd string, timeout time.Duration) ([]string, error) {
+	if err := m.acquire(ctx); err != nil {
+		return nil, err
+	}
+
+	responses, detached, err := m.exchange(ctx, cmd, cmd, timeout)
+	if !detached {
+		m.release()
+	}
+	return responses, err
+}
+
+// sendWithPrompt 发送需要 ">" 提示符的命令及其数据，整个过程持有命令锁
+//
+// data 以 Ctrl+Z 结尾提交；提示符之后出错时发送 ESC 取消输入。
+func (m *Device) sendWithPrompt(ctx context.Context, cmd, data string, timeout time.Duration) ([]string, error) {
+	if err := m.acquire(ctx); err != nil {
+		return nil, err
+	}
+
+	responses, detached, err := m.exchange(ctx, cmd, cmd, m.commandTimeout(cmd))
+	if detached {
+		// 后台等到迟到的提示符时发送 ESC，见 discardResponse
+		return responses, err
+	}
+	if err == nil && !strings.HasPrefix(responses[len(responses)-1], m.responses.Prompt) {
+		err = fmt.Errorf("expected response %q not found in %v", m.responses.Prompt, responses)
+	}
+	if err != nil {
+		m.release()
+		return responses, err
+	}
+
+	// 数据的响应（如 +CMGS: <mr>）归属于发起提示的命令
+	if ctx.Err() != nil {
+		m.writeString("\x1B")
+		m.release()
+		return nil, ctx.Err()
+	}
+	responses, detached, err = m.exchange(ctx, data+"\x1A", cmd, timeout)
+	if !detached {
+		m.release()
+	}
+	return responses, err
+}
+
+// acquire 获取命令锁，等待期间同样响应取消
+func (m *Device) acquire(ctx context.Context) error {
 	if m.closed.Load() {
-		return nil, fmt.Errorf("device closed")
+		return ErrClosed
 	}
 
-	// 加锁保护
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	select {
+	case m.lock <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 
+	// 数据模式期间串口上是原始数据，不能发送命令
+	if m.data.Load() != nil {
+		<-m.lock
+		return ErrDataMode
+	}
+	return nil
+}
+
+// release 释放命令锁
+func (m *Device) release() {
+	m.cmd.Store("")
+	m.echoLine.Store("")
+	<-m.lock
+}
+
+// exchange 写入命令并读取响应，调用方需持有命令锁
+//
+// name 为用于区分响应与通知的命令名；命令被取消时返回 detached，
+// 此时由后台协程读完残留响应后释放锁，调用方不得再释放。
+func (m *Device) exchange(ctx context.Context, cmd, name string, timeout time.Duration) ([]string, bool, error) {
 	// 清空响应通道，避免收到残留响应
 	for len(m.responseChan) > 0 {
 		<-m.responseChan
@@ -139,21 +298,44 @@ func (m *Device) SendCommand(cmd string) ([]string, error) {
 		cmd = cmd + Terminators[0]
 	}
 
-	// 记录正在执行的命令
-	m.cmd.Store(cmd)
-	defer m.cmd.Store("")
+	// 记录正在执行的命令和预期的回显
+	m.cmd.Store(name)
+	m.echoLine.Store(strings.TrimSpace(strings.TrimRight(cmd, strings.Join(Terminators, ""))))
 
 	// 向串口写入命令
 	if err := m.writeString(cmd); err != nil {
-		return nil, err
+		return nil, false, err
+	}
+
+	// ctx 的截止时间与命令超时同时生效，先到者为准
+	responses, err := m.readResponse(ctx, timeout)
+	if err != nil && ctx.Err() != nil {
+		// 命令已发出但调用方放弃等待，后台读完残留响应后再释放锁，
+		// 避免下一条命令读到错位的响应
+		go m.discardResponse(m.commandTimeout(name))
+		return responses, true, err
+	}
+	if err != nil {
+		return responses, false, err
 	}
 
-	return m.readResponse()
+	// 最终响应为错误结果码时返回 *Error
+	if e := m.responses.ParseError(responses[len(responses)-1]); e != nil {
+		e.Command = strings.TrimRight(name, strings.Join(Terminators, ""))
+		return responses, false, e
+	}
+
+	return responses, false, nil
 }
 
 // SendCommandExpect 发送命令并期望特定响应
 func (m *Device) SendCommandExpect(cmd string, expected string) error {
-	responses, err := m.SendCommand(cmd)
+	return m.SendCommandExpectContext(context.Background(), cmd, expected)
+}
+
+// SendCommandExpectContext 发送命令并期望特定响应，支持通过 ctx 取消
+func (m *Device) SendCommandExpectContext(ctx context.Context, cmd string, expected string) error {
+	responses, err := m.SendCommandContext(ctx, cmd)
 	if err != nil {
 		return err
 	}
@@ -168,85 +350,297 @@ func (m *Device) SendCommandExpect(cmd string, expected string) error {
 	return fmt.Errorf("expected response %q not found in %v", expected, responses)
 }
 
-// readResponse 从响应通道读取响应
-func (m *Device) readResponse() ([]string, error) {
+// SendCommandInfo 发送命令，分别返回信息行和最终结果码
+//
+// 信息行不含命令回显和最终结果码，如 AT+CSQ 返回 ["+CSQ: 20,99"] 和 "OK"。
+func (m *Device) SendCommandInfo(cmd string) ([]string, string, error) {
+	return m.SendCommandInfoContext(context.Background(), cmd)
+}
+
+// SendCommandInfoContext 发送命令，分别返回信息行和最终结果码
+func (m *Device) SendCommandInfoContext(ctx context.Context, cmd string) ([]string, string, error) {
+	resp, err := m.SendCommandResponseContext(ctx, cmd)
+	return resp.Lines, resp.Final, err
+}
+
+// splitFinal 拆分信息行和最终结果码，没有最终结果码（如超时）时返回空字符串
+func (m *Device) splitFinal(responses []string) ([]string, string) {
+	if n := len(responses); n > 0 && m.responses.IsFinal(responses[n-1]) {
+		return responses[:n-1], responses[n-1]
+	}
+	return responses, ""
+}
+
+// commandTimeout 返回命令的超时时间
+func (m *Device) commandTimeout(cmd string) time.Duration {
+	if timeout := m.commands.Timeout(cmd); timeout > 0 {
+		return timeout
+	}
+	return m.timeout
+}
+
+// readResponse 从响应通道读取响应，timeout 为 0 时仅受 ctx 控制
+func (m *Device) readResponse(ctx context.Context, timeout time.Duration) ([]string, error) {
 	var responses []string
-	timeout := time.After(m.timeout)
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
 
 	for {
 		select {
-		case line, ok := <-m.responseChan:
-			if !ok {
-				return responses, fmt.Errorf("device closed")
-			}
-
+		case line := <-m.responseChan:
 			responses = append(responses, line)
 			if m.responses.IsFinal(line) {
 				return responses, nil
 			}
 
-		case <-timeout:
-			return responses, fmt.Errorf("command timeout")
+		case <-expired:
+			return responses, ErrTimeout
+
+		case <-m.done:
+			return responses, ErrClosed
+
+		case <-ctx.Done():
+			return responses, ctx.Err()
 		}
 	}
 }
 
+// discardResponse 丢弃被取消命令的剩余响应，直到最终响应或超时，然后释放锁
+func (m *Device) discardResponse(timeout time.Duration) {
+	defer m.release()
+
+	responses, err := m.readResponse(context.Background(), timeout)
+	if err != nil {
+		m.printf("cancelled command: %v", err)
+	}
+	for _, line := range responses {
+		m.printf("discarding data: %s", line)
+	}
+
+	// 被取消的命令已等到提示符时，发送 ESC 取消输入，模块才会接受下一条命令
+	if n := len(responses); err == nil && strings.HasPrefix(responses[n-1], m.responses.Prompt) {
+		m.writeString("\x1B")
+	}
+
+	// 被取消的 EnterDataMode 在此之前一直登记着连接，模块已以 CONNECT 应答时转义回命令模式，不挂断
+	if c := m.data.Load(); c != nil {
+		if c.isOnline() {
+			if err := m.escapeDataMode(c); err != nil {
+				m.printf("cancelled data mode: %v", err)
+			}
+		}
+		c.finish(ErrClosed)
+	}
+}
+
 // ===== 原生读写 =====
 
-// readAndDispatch 从串口读取数据并分发
+// readAndDispatch 从串口读取数据并分发，配置了串口工厂时负责在串口失效后重新打开
 func (m *Device) readAndDispatch() {
-	reader := bufio.NewReader(m.port)
 	for {
+		port := m.getPort()
+		if port == nil {
+			if m.portFactory == nil || !m.reconnect() {
+				return
+			}
+			continue
+		}
+
+		err := m.readLines(port)
 		if m.closed.Load() {
 			return
 		}
 
-		line, err := reader.ReadString('\n')
+		m.printf("port error: %v", err)
+		if c := m.data.Load(); c != nil {
+			c.finish(err)
+		}
+		m.setState(StateDisconnected, 0, err)
+		m.dropPort(port)
+		if !m.reconnect() {
+			return
+		}
+	}
+}
+
+// readLines 逐行读取并分发，直到设备关闭或串口失效
+//
+// 读取超时（Timeout() 为 true 的错误）表示暂无数据，io.EOF 等其他错误表示串口失效；
+// 未配置串口工厂时其他错误也只记录日志后重试，否则视为串口失效并返回。
+func (m *Device) readLines(port Port) error {
+	buf := make([]byte, 1024)
+	pending := ""    // 尚未遇到换行符的数据
+	payloadURC := "" // 等待数据行的多行通知头部
+	for {
+		if m.closed.Load() {
+			return ErrClosed
+		}
+
+		n, err := port.Read(buf)
+		if n > 0 {
+			pending += string(buf[:n])
+			for pending != "" {
+				// 数据模式下透传原始数据，直到 NO CARRIER
+				if c := m.dataConn(); c != nil {
+					pending = string(c.feed([]byte(pending)))
+					continue
+				}
+
+				i := strings.IndexByte(pending, '\n')
+				if i < 0 {
+					break
+				}
+				payloadURC = m.handleLine(pending[:i], payloadURC)
+				pending = pending[i+1:]
+			}
+
+			// 输入提示符 "> " 之后没有换行，模块会一直等待输入
+			if strings.TrimSpace(pending) == m.responses.Prompt {
+				payloadURC = m.handleLine(pending, payloadURC)
+				pending = ""
+			}
+		}
+
 		if err != nil {
-			if err != io.EOF {
+			if m.closed.Load() {
+				return err
+			}
+			if !isTransient(err) {
+				if m.portFactory != nil {
+					return err
+				}
 				m.printf("read error: %v", err)
 			}
-			time.Sleep(m.timeout / 2)
-			continue
+			if n == 0 {
+				time.Sleep(m.timeout / 2)
+			}
 		}
+	}
+}
 
-		// 去除空白字符
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+// handleLine 将一行数据分发为通知或命令响应，返回仍在等待数据行的多行通知头部
+func (m *Device) handleLine(line, payloadURC string) string {
+	// 去除空白字符
+	line = strings.TrimSpace(line)
+	if line == "" {
+		// 文本模式短信正文中的空行需保留，其余空行只是行分隔
+		if payloadURC == "" && m.keepBlankLines() {
+			select {
+			case m.responseChan <- line:
+			default:
+			}
 		}
+		return payloadURC
+	}
 
-		m.printf("read line: %s", line)
+	m.printf("read line: %s", line)
 
-		// 处理通知消息
-		cmd := m.cmd.Load().(string)
-		if m.notifications.IsNotification(line, cmd) {
-			if m.urcHandler != nil {
-				go m.urcHandler(parseParam(line))
-			}
-			continue
+	// 多行通知的数据行
+	if payloadURC != "" {
+		m.publish(payloadURC, line)
+		return ""
+	}
+
+	// 命令回显与写入的命令完全一致（提示符后的数据回显可能带有 Ctrl+Z）
+	echo := m.echoLine.Load().(string)
+	if echo != "" && strings.EqualFold(strings.Trim(line, "\x1A\x1B"), echo) {
+		m.echoLine.Store("")
+		m.echo.Store(echoOn)
+		return ""
+	}
+
+	// 等待进入数据模式的命令收到 CONNECT，其后的数据不再按行处理
+	if c := m.data.Load(); c != nil && strings.HasPrefix(line, m.responses.Connect) {
+		c.goOnline()
+	}
+
+	// 处理通知消息
+	cmd := m.cmd.Load().(string)
+	if m.notifications.IsNotification(line, cmd) && !(cmd != "" && (m.isErrorResult(line) || m.isCallResult(line, cmd))) {
+		if label, param := parseParam(line); m.notifications.HasPayload(label, param) {
+			return line
 		}
+		m.publish(line, "")
+		return ""
+	}
 
-		// 将数据写入响应通道
-		select {
-		case m.responseChan <- line:
-		default:
-			// 通道满了，丢弃数据（避免阻塞）
-			m.printf("discarding data: %s", line)
+	// 回显之前先收到响应，说明回显已关闭（提示符后的数据不一定回显，不作判断）
+	if echo != "" {
+		m.echoLine.Store("")
+		if commandName(echo) != "" || strings.HasPrefix(strings.ToUpper(echo), "AT") {
+			m.echo.Store(echoOff)
 		}
 	}
+
+	// 最终响应之后的同名行（如紧随 OK 的 +CUSD）属于通知，需在调用方收到响应、释放锁之前清除命令
+	if m.responses.IsFinal(line) {
+		m.cmd.Store("")
+		m.echoLine.Store("")
+	}
+
+	// 将数据写入响应通道
+	select {
+	case m.responseChan <- line:
+	default:
+		// 通道满了，丢弃数据（避免阻塞）
+		m.printf("discarding data: %s", line)
+	}
+	return ""
+}
+
+// keepBlankLines 当前命令是否为文本模式的短信列表或读取，其响应中的空行属于正文
+func (m *Device) keepBlankLines() bool {
+	if m.smsMode.Load() != 1 {
+		return false
+	}
+	cmd := m.cmd.Load().(string)
+	return cmd != "" && (strings.HasPrefix(cmd, m.commands.ListSMS) || strings.HasPrefix(cmd, m.commands.ReadSMS))
+}
+
+// isErrorResult 检查是否为 +CME ERROR/+CMS ERROR，命令执行期间它们是该命令的最终响应而非通知
+func (m *Device) isErrorResult(line string) bool {
+	for _, item := range []string{m.responses.CMEError, m.responses.CMSError} {
+		if item != "" && strings.HasPrefix(line, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCallResult 检查是否为 NO CARRIER/BUSY 等结果码，且当前命令为拨号、接听、进入数据模式的命令或退出数据模式，
+// 此时它们是该命令的最终响应而非通知
+func (m *Device) isCallResult(line, cmd string) bool {
+	cmd = strings.ToUpper(strings.TrimSpace(cmd))
+	if !strings.HasPrefix(cmd, "ATD") && !strings.HasPrefix(cmd, "ATA") && cmd != escapeSequence && m.data.Load() == nil {
+		return false
+	}
+	for _, item := range []string{m.responses.NoCarrier, m.responses.Busy, m.responses.NoAnswer, m.responses.NoDialtone} {
+		if item != "" && strings.HasPrefix(line, item) {
+			return true
+		}
+	}
+	return false
 }
 
 // writeString 写入数据到串口
 func (m *Device) writeString(data string) error {
 	if m.closed.Load() {
-		return fmt.Errorf("device closed")
+		return ErrClosed
 	}
 
 	m.printf("write cmd: %s", data)
 
+	port := m.getPort()
+	if port == nil {
+		return ErrDisconnected
+	}
+
 	// 向串口写入数据
-	n, err := m.port.Write([]byte(data))
+	n, err := port.Write([]byte(data))
 	if err != nil {
 		return fmt.Errorf("failed to write: %w", err)
 	}
@@ -265,17 +659,20 @@ func parseInt(s string) int {
 	return v
 }
 
-// parseParam 解析响应内容
+// parseParam 解析响应内容，返回标签和参数；语法错误时保留已解析的参数
 func parseParam(line string) (string, map[int]string) {
-	parts := strings.SplitN(line, ":", 2)
-	if len(parts) == 2 {
-		label := strings.TrimSpace(parts[0])
-		group := strings.Split(strings.TrimSpace(parts[1]), ",")
-		param := map[int]string{}
-		for i, v := range group {
-			param[i] = strings.Trim(strings.TrimSpace(v), `"'`)
-		}
-		return label, param
+	if !strings.Contains(line, ":") {
+		return line, nil
+	}
+	label, values, _ := ParseLine(line)
+	return label, newParams(values)
+}
+
+// newParams 将解析后的参数按位置索引，字符串去除引号，列表保留括号
+func newParams(values []Value) map[int]string {
+	param := map[int]string{}
+	for i, v := range values {
+		param[i] = v.Text
 	}
-	return line, nil
+	return param
 }
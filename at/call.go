@@ -0,0 +1,360 @@
+package at
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EventCall 通话状态事件类型，由设备根据 RING、+CLIP、NO CARRIER、BUSY 等通知产生
+const EventCall = "CALL"
+
+// CallState 设备的通话状态
+type CallState int
+
+const (
+	CallIdle    CallState = iota // 无通话
+	CallRinging                  // 来电响铃
+	CallDialing                  // 正在呼出
+	CallActive                   // 通话中
+)
+
+func (s CallState) String() string {
+	switch s {
+	case CallIdle:
+		return "idle"
+	case CallRinging:
+		return "ringing"
+	case CallDialing:
+		return "dialing"
+	case CallActive:
+		return "active"
+	}
+	return "unknown(" + strconv.Itoa(int(s)) + ")"
+}
+
+// CallEvent 通话状态变化
+type CallEvent struct {
+	State  CallState `json:"state"`  // 新状态
+	Number string    `json:"number"` // 对方号码，未知时为空
+	Reason string    `json:"reason"` // 通话结束的结果码，如 "NO CARRIER"、"BUSY"，主动挂断时为空
+}
+
+// CallStat 单个通话的状态（+CLCC 的 <stat>）
+type CallStat int
+
+const (
+	CallStatActive   CallStat = 0 // 通话中
+	CallStatHeld     CallStat = 1 // 已保持
+	CallStatDialing  CallStat = 2 // 正在拨号（呼出）
+	CallStatAlerting CallStat = 3 // 对方振铃（呼出）
+	CallStatIncoming CallStat = 4 // 来电（呼入）
+	CallStatWaiting  CallStat = 5 // 呼叫等待（呼入）
+)
+
+func (s CallStat) String() string {
+	switch s {
+	case CallStatActive:
+		return "active"
+	case CallStatHeld:
+		return "held"
+	case CallStatDialing:
+		return "dialing"
+	case CallStatAlerting:
+		return "alerting"
+	case CallStatIncoming:
+		return "incoming"
+	case CallStatWaiting:
+		return "waiting"
+	}
+	return "unknown(" + strconv.Itoa(int(s)) + ")"
+}
+
+// CallMode 通话类型（+CLCC 的 <mode>）
+type CallMode int
+
+const (
+	CallModeVoice CallMode = 0 // 语音
+	CallModeData  CallMode = 1 // 数据
+	CallModeFax   CallMode = 2 // 传真
+)
+
+func (c CallMode) String() string {
+	switch c {
+	case CallModeVoice:
+		return "voice"
+	case CallModeData:
+		return "data"
+	case CallModeFax:
+		return "fax"
+	}
+	return "unknown(" + strconv.Itoa(int(c)) + ")"
+}
+
+// Call 当前通话
+type Call struct {
+	Index      int          `json:"index"`      // 通话编号，用于 ReleaseCall、SplitCall
+	Incoming   bool         `json:"incoming"`   // 是否为呼入
+	Stat       CallStat     `json:"stat"`       // 通话状态
+	Mode       CallMode     `json:"mode"`       // 通话类型
+	Multiparty bool         `json:"multiparty"` // 是否为多方通话的一部分
+	Number     string       `json:"number"`     // 对方号码
+	Type       TypeOfNumber `json:"type"`       // 号码类型
+	Alpha      string       `json:"alpha"`      // 电话簿中的名称，已按 TE 字符集解码
+}
+
+// ListCalls 查询当前通话列表（AT+CLCC），没有通话时返回空列表
+//
+// 查询结果同时用于校正 CallState 返回的通话状态。
+func (m *Device) ListCalls() ([]Call, error) {
+	return m.ListCallsContext(context.Background())
+}
+
+// ListCallsContext 查询当前通话列表
+func (m *Device) ListCallsContext(ctx context.Context) ([]Call, error) {
+	resp, err := m.SendCommandResponseContext(ctx, m.commands.ListCalls)
+	if err != nil {
+		return nil, err
+	}
+
+	charset := m.currentCharset()
+	calls := []Call{}
+	for _, param := range resp.All("+CLCC") {
+		if call, ok := parseCall(charset, param.Map()); ok {
+			calls = append(calls, call)
+		}
+	}
+	m.syncCalls(calls)
+	return calls, nil
+}
+
+// DialData 拨打数据电话（CSD），收到 CONNECT 后进入数据模式
+func (m *Device) DialData(number string) (*DataConn, error) {
+	return m.DialDataContext(context.Background(), number)
+}
+
+// DialDataContext 拨打数据电话，收到 CONNECT 后进入数据模式
+func (m *Device) DialDataContext(ctx context.Context, number string) (*DataConn, error) {
+	return m.EnterDataModeContext(ctx, m.commands.Dial+strings.TrimSuffix(number, ";"))
+}
+
+// SendDTMF 在通话中发送 DTMF 音（0-9、*、#、A-D），duration 为每个音的时长，为 0 时使用模块默认值
+func (m *Device) SendDTMF(tones string, duration time.Duration) error {
+	return m.SendDTMFContext(context.Background(), tones, duration)
+}
+
+// SendDTMFContext 在通话中发送 DTMF 音
+func (m *Device) SendDTMFContext(ctx context.Context, tones string, duration time.Duration) error {
+	tones = strings.ToUpper(tones)
+	for _, r := range tones {
+		if !strings.ContainsRune("0123456789*#ABCD", r) {
+			return fmt.Errorf("invalid DTMF tone %q", r)
+		}
+	}
+
+	// 逐个发送，兼容只接受单个音的模块
+	for _, r := range tones {
+		cmd := fmt.Sprintf("%s=%c", m.commands.DTMF, r)
+		if duration > 0 {
+			// 时长单位为 1/10 秒
+			cmd += fmt.Sprintf(",%d", max(1, int(duration/(100*time.Millisecond))))
+		}
+		if _, err := m.sendCommand(ctx, cmd, m.commandTimeout(cmd)+duration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseHeldCalls 挂断所有保持的通话，或拒接等待中的来电
+func (m *Device) ReleaseHeldCalls() error {
+	return m.ReleaseHeldCallsContext(context.Background())
+}
+
+// ReleaseHeldCallsContext 挂断所有保持的通话，或拒接等待中的来电
+func (m *Device) ReleaseHeldCallsContext(ctx context.Context) error {
+	return m.holdCall(ctx, "0")
+}
+
+// ReleaseActiveCalls 挂断当前通话，并接听等待中的来电或恢复保持的通话
+func (m *Device) ReleaseActiveCalls() error {
+	return m.ReleaseActiveCallsContext(context.Background())
+}
+
+// ReleaseActiveCallsContext 挂断当前通话，并接听等待中的来电或恢复保持的通话
+func (m *Device) ReleaseActiveCallsContext(ctx context.Context) error {
+	return m.holdCall(ctx, "1")
+}
+
+// ReleaseCall 挂断指定编号的通话
+func (m *Device) ReleaseCall(index int) error {
+	return m.ReleaseCallContext(context.Background(), index)
+}
+
+// ReleaseCallContext 挂断指定编号的通话
+func (m *Device) ReleaseCallContext(ctx context.Context, index int) error {
+	return m.holdCall(ctx, "1"+strconv.Itoa(index))
+}
+
+// SwapCalls 保持当前通话，并接听等待中的来电或恢复保持的通话
+func (m *Device) SwapCalls() error {
+	return m.SwapCallsContext(context.Background())
+}
+
+// SwapCallsContext 保持当前通话，并接听等待中的来电或恢复保持的通话
+func (m *Device) SwapCallsContext(ctx context.Context) error {
+	return m.holdCall(ctx, "2")
+}
+
+// SplitCall 保持除指定编号以外的所有通话，用于从多方通话中单独通话
+func (m *Device) SplitCall(index int) error {
+	return m.SplitCallContext(context.Background(), index)
+}
+
+// SplitCallContext 保持除指定编号以外的所有通话
+func (m *Device) SplitCallContext(ctx context.Context, index int) error {
+	return m.holdCall(ctx, "2"+strconv.Itoa(index))
+}
+
+// Conference 将保持的通话加入当前通话，建立多方通话
+func (m *Device) Conference() error {
+	return m.ConferenceContext(context.Background())
+}
+
+// ConferenceContext 将保持的通话加入当前通话
+func (m *Device) ConferenceContext(ctx context.Context) error {
+	return m.holdCall(ctx, "3")
+}
+
+// holdCall 发送呼叫保持和多方通话操作（AT+CHLD）
+func (m *Device) holdCall(ctx context.Context, action string) error {
+	return m.SendCommandExpectContext(ctx, m.commands.CallHold+"="+action, "OK")
+}
+
+// CallState 返回根据通知和命令结果推断的通话状态
+//
+// 多路通话时只反映整体状态，各通话的详细状态通过 ListCalls 查询。
+func (m *Device) CallState() CallState {
+	m.callMu.Lock()
+	defer m.callMu.Unlock()
+	return m.call.State
+}
+
+// setCall 更新通话状态，状态或号码变化时发布通话事件
+func (m *Device) setCall(state CallState, number, reason string) {
+	m.callMu.Lock()
+	prev := m.call
+	if number == "" && state != CallIdle {
+		number = prev.Number
+	}
+	if state == prev.State && number == prev.Number {
+		m.callMu.Unlock()
+		return
+	}
+
+	data := CallEvent{State: state, Number: number, Reason: reason}
+	m.call = CallEvent{State: state, Number: number}
+	if state == CallIdle {
+		// 结束事件带上已结束通话的号码
+		data.Number = prev.Number
+		m.call = CallEvent{State: CallIdle}
+	}
+	m.callMu.Unlock()
+
+	m.dispatch(Event{
+		Kind: EventCall,
+		Line: state.String(),
+		Data: data,
+		Time: time.Now(),
+	})
+}
+
+// trackCall 根据通知推进通话状态
+func (m *Device) trackCall(event Event) {
+	switch data := event.Data.(type) {
+	case RingEvent:
+		if m.CallState() == CallIdle {
+			m.setCall(CallRinging, "", "")
+		}
+
+	case CallerIDEvent:
+		if state := m.CallState(); state == CallIdle || state == CallRinging {
+			m.setCall(CallRinging, data.Number, "")
+		}
+
+	case Call:
+		// 部分模块在 AT+CLCC=1 后主动上报通话变化，未知状态（如 6 已断开）视为通话结束
+		switch data.Stat {
+		case CallStatActive, CallStatHeld:
+			m.setCall(CallActive, data.Number, "")
+		case CallStatDialing, CallStatAlerting:
+			m.setCall(CallDialing, data.Number, "")
+		case CallStatIncoming, CallStatWaiting:
+			if state := m.CallState(); state == CallIdle || state == CallRinging {
+				m.setCall(CallRinging, data.Number, "")
+			}
+		default:
+			m.setCall(CallIdle, "", "")
+		}
+
+	default:
+		ns := &m.notifications
+		switch event.Kind {
+		case ns.NoCarrier, ns.Busy, ns.NoAnswer, ns.NoDialtone:
+			m.setCall(CallIdle, "", event.Kind)
+		}
+	}
+}
+
+// syncCalls 根据通话列表校正通话状态
+func (m *Device) syncCalls(calls []Call) {
+	state, number := CallIdle, ""
+	rank := map[CallState]int{CallIdle: 0, CallRinging: 1, CallDialing: 2, CallActive: 3}
+	for _, call := range calls {
+		s := CallRinging
+		switch call.Stat {
+		case CallStatActive, CallStatHeld:
+			s = CallActive
+		case CallStatDialing, CallStatAlerting:
+			s = CallDialing
+		}
+		if rank[s] > rank[state] {
+			state, number = s, call.Number
+		}
+	}
+	m.setCall(state, number, "")
+}
+
+// endCall 通话命令以 NO CARRIER、BUSY 等结果码结束时更新通话状态
+func (m *Device) endCall(err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		return
+	}
+	switch e.Kind {
+	case ErrorNoCarrier, ErrorBusy, ErrorNoAnswer, ErrorNoDialtone:
+		m.setCall(CallIdle, "", e.Kind.String())
+	}
+}
+
+// parseCall 解析 +CLCC 参数
+func parseCall(charset string, param map[int]string) (Call, bool) {
+	// 格式: +CLCC: 1,0,0,0,0,"+8613800138000",145,"Alice"
+	index, err := strconv.Atoi(param[0])
+	if err != nil || len(param) < 5 {
+		return Call{}, false
+	}
+	return Call{
+		Index:      index,
+		Incoming:   param[1] == "1",
+		Stat:       CallStat(parseInt(param[2])),
+		Mode:       CallMode(parseInt(param[3])),
+		Multiparty: param[4] == "1",
+		Number:     decodeNumber(charset, param[5]),
+		Type:       TypeOfNumber(parseInt(param[6])),
+		Alpha:      decodeTextField(charset, param[7]),
+	}, true
+}
@@ -0,0 +1,201 @@
+package at_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehiy/modem/at"
+)
+
+// waitCall 等待指定状态的通话事件
+func waitCall(t *testing.T, events <-chan at.Event, state at.CallState) at.CallEvent {
+	t.Helper()
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if data := event.Data.(at.CallEvent); data.State == state {
+				return data
+			}
+		case <-timeout:
+			t.Fatalf("call state %s not reached", state)
+		}
+	}
+}
+
+// lastCommands 返回模拟器最近收到的 n 条命令
+func lastCommands(history []string, n int) []string {
+	if len(history) < n {
+		return history
+	}
+	return history[len(history)-n:]
+}
+
+func TestIncomingCall(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`ATA`, "OK")
+	events, cancel := d.Subscribe(at.EventCall)
+	defer cancel()
+
+	s.URC("RING")
+	if data := waitCall(t, events, at.CallRinging); data.Number != "" {
+		t.Fatalf("RING event = %+v", data)
+	}
+
+	// +CLIP 补充来电号码，状态保持响铃
+	s.URC(`+CLIP: "+8613800138000",145,,,"",0`)
+	if data := waitCall(t, events, at.CallRinging); data.Number != "+8613800138000" {
+		t.Fatalf("+CLIP event = %+v", data)
+	}
+
+	if err := d.Answer(); err != nil {
+		t.Fatalf("Answer: %v", err)
+	}
+	if data := waitCall(t, events, at.CallActive); data.Number != "+8613800138000" {
+		t.Fatalf("active event = %+v", data)
+	}
+	if d.CallState() != at.CallActive {
+		t.Fatalf("CallState = %s", d.CallState())
+	}
+
+	// 对方挂断，结束事件带上已结束通话的号码和结果码
+	s.URC("NO CARRIER")
+	data := waitCall(t, events, at.CallIdle)
+	if data.Number != "+8613800138000" || data.Reason != "NO CARRIER" {
+		t.Fatalf("idle event = %+v", data)
+	}
+	if d.CallState() != at.CallIdle {
+		t.Fatalf("CallState = %s", d.CallState())
+	}
+}
+
+func TestDial(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`ATD10086;`, "OK")
+	s.Handle(`ATD10010;`, "BUSY")
+	s.Handle(`ATH`, "OK")
+	events, cancel := d.Subscribe(at.EventCall)
+	defer cancel()
+
+	// 语音呼叫以 ";" 结尾，号码已带 ";" 时不重复添加
+	for _, number := range []string{"10086", "10086;"} {
+		if err := d.Dial(number); err != nil {
+			t.Fatalf("Dial(%q): %v", number, err)
+		}
+		history := s.History()
+		if cmd := history[len(history)-1]; cmd != "ATD10086;" {
+			t.Fatalf("Dial(%q) sent %q", number, cmd)
+		}
+	}
+	if data := waitCall(t, events, at.CallDialing); data.Number != "10086" {
+		t.Fatalf("dialing event = %+v", data)
+	}
+
+	if err := d.Hangup(); err != nil {
+		t.Fatalf("Hangup: %v", err)
+	}
+	if data := waitCall(t, events, at.CallIdle); data.Number != "10086" || data.Reason != "" {
+		t.Fatalf("hangup event = %+v", data)
+	}
+
+	// 拨号以 BUSY 结束时返回错误，状态回到空闲
+	if err := d.Dial("10010"); err == nil {
+		t.Fatal("Dial to busy number succeeded")
+	}
+	if d.CallState() != at.CallIdle {
+		t.Fatalf("CallState = %s", d.CallState())
+	}
+}
+
+func TestListCalls(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`AT\+CLCC`,
+		`+CLCC: 1,0,0,0,1,"+8613800138000",145`,
+		`+CLCC: 2,1,1,0,1,"10086",129,"Alice"`,
+		"OK")
+	events, cancel := d.Subscribe(at.EventCall)
+	defer cancel()
+
+	calls, err := d.ListCalls()
+	if err != nil {
+		t.Fatalf("ListCalls: %v", err)
+	}
+	want := []at.Call{
+		{Index: 1, Stat: at.CallStatActive, Multiparty: true, Number: "+8613800138000", Type: 145},
+		{Index: 2, Incoming: true, Stat: at.CallStatHeld, Multiparty: true, Number: "10086", Type: 129, Alpha: "Alice"},
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("ListCalls = %+v", calls)
+	}
+
+	// 查询结果校正通话状态
+	if data := waitCall(t, events, at.CallActive); data.Number != "+8613800138000" {
+		t.Fatalf("active event = %+v", data)
+	}
+
+	// 主动上报的 +CLCC 中未知状态视为通话结束
+	s.URC(`+CLCC: 1,0,6,0,0,"+8613800138000",145`)
+	waitCall(t, events, at.CallIdle)
+}
+
+func TestSendDTMF(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`AT\+VTS=.*`, "OK")
+
+	if err := d.SendDTMF("1a#", 250*time.Millisecond); err != nil {
+		t.Fatalf("SendDTMF: %v", err)
+	}
+	want := []string{"AT+VTS=1,2", "AT+VTS=A,2", "AT+VTS=#,2"}
+	if got := lastCommands(s.History(), 3); !reflect.DeepEqual(got, want) {
+		t.Fatalf("commands = %q, want %q", got, want)
+	}
+
+	if err := d.SendDTMF("5", 0); err != nil {
+		t.Fatalf("SendDTMF: %v", err)
+	}
+	if got := lastCommands(s.History(), 1); got[0] != "AT+VTS=5" {
+		t.Fatalf("commands = %q", got)
+	}
+
+	// 无效的音在发送前被拒绝
+	count := len(s.History())
+	if err := d.SendDTMF("1x", 0); err == nil {
+		t.Fatal("SendDTMF with invalid tone succeeded")
+	}
+	if len(s.History()) != count {
+		t.Fatalf("commands sent = %q", s.History()[count:])
+	}
+}
+
+func TestMultiparty(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`AT\+CHLD=.*`, "OK")
+
+	actions := []func() error{
+		d.ReleaseHeldCalls,
+		d.ReleaseActiveCalls,
+		func() error { return d.ReleaseCall(2) },
+		d.SwapCalls,
+		func() error { return d.SplitCall(1) },
+		d.Conference,
+	}
+	for _, action := range actions {
+		if err := action(); err != nil {
+			t.Fatalf("AT+CHLD: %v", err)
+		}
+	}
+
+	want := []string{"AT+CHLD=0", "AT+CHLD=1", "AT+CHLD=12", "AT+CHLD=2", "AT+CHLD=21", "AT+CHLD=3"}
+	got := []string{}
+	for _, cmd := range s.History() {
+		if strings.HasPrefix(cmd, "AT+CHLD") {
+			got = append(got, cmd)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("commands = %q, want %q", got, want)
+	}
+}
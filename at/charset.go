@@ -0,0 +1,170 @@
+package at
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/rehiy/modem/sms/gsm7"
+	"github.com/rehiy/modem/sms/ucs2"
+)
+
+// TE 字符集（AT+CSCS）
+const (
+	CharsetGSM    = "GSM"    // GSM 7 位默认字母表
+	CharsetIRA    = "IRA"    // 国际参考字母表（ASCII）
+	CharsetUCS2   = "UCS2"   // UCS2 十六进制编码
+	CharsetHex    = "HEX"    // GSM 7 位默认字母表的十六进制编码
+	CharsetLatin1 = "8859-1" // ISO 8859 Latin 1
+	CharsetUTF8   = "UTF-8"  // UTF-8（厂商扩展）
+)
+
+// SetCharset 设置 TE 字符集，影响文本模式短信、电话簿等字符串参数的编码
+func (m *Device) SetCharset(charset string) error {
+	return m.SetCharsetContext(context.Background(), charset)
+}
+
+// SetCharsetContext 设置 TE 字符集
+func (m *Device) SetCharsetContext(ctx context.Context, charset string) error {
+	cmd := fmt.Sprintf(`%s="%s"`, m.commands.Charset, charset)
+	if err := m.SendCommandExpectContext(ctx, cmd, "OK"); err != nil {
+		return err
+	}
+	m.charset.Store(strings.ToUpper(charset))
+	return nil
+}
+
+// GetCharset 查询 TE 字符集
+func (m *Device) GetCharset() (string, error) {
+	return m.GetCharsetContext(context.Background())
+}
+
+// GetCharsetContext 查询 TE 字符集
+func (m *Device) GetCharsetContext(ctx context.Context) (string, error) {
+	resp, err := m.SendCommandResponseContext(ctx, m.commands.Charset+"?")
+	if err != nil {
+		return "", err
+	}
+
+	// 格式: +CSCS: "GSM"
+	if param, ok := resp.Params("+CSCS"); ok && len(param) >= 1 {
+		charset := strings.ToUpper(param.String(0))
+		m.charset.Store(charset)
+		return charset, nil
+	}
+
+	return "", fmt.Errorf("failed to parse charset")
+}
+
+// currentCharset 返回最近一次设置或查询到的字符集，未知时返回空字符串（按原样传输）
+func (m *Device) currentCharset() string {
+	charset, _ := m.charset.Load().(string)
+	return charset
+}
+
+// encodeString 将 UTF-8 字符串按 TE 字符集编码
+func encodeString(charset, s string) (string, error) {
+	switch strings.ToUpper(charset) {
+	case CharsetUCS2:
+		return strings.ToUpper(hex.EncodeToString(ucs2.Encode([]rune(s)))), nil
+
+	case CharsetGSM:
+		septets, err := gsm7.Encode([]byte(s))
+		if err != nil {
+			return "", err
+		}
+		return string(septets), nil
+
+	case CharsetHex:
+		septets, err := gsm7.Encode([]byte(s))
+		if err != nil {
+			return "", err
+		}
+		return strings.ToUpper(hex.EncodeToString(septets)), nil
+
+	case CharsetLatin1:
+		b := make([]byte, 0, len(s))
+		for _, r := range s {
+			if r > 0xFF {
+				return "", fmt.Errorf("character %q not in %s", r, charset)
+			}
+			b = append(b, byte(r))
+		}
+		return string(b), nil
+	}
+
+	// IRA、UTF-8 等按原样传输
+	return s, nil
+}
+
+// quoteString 将字符串参数放入双引号
+//
+// 字符串常量不支持转义，参数中的双引号会提前结束参数，CR、LF 会提前结束命令，此时返回错误。
+func quoteString(s string) (string, error) {
+	if strings.ContainsAny(s, "\"\r\n") {
+		return "", fmt.Errorf("string parameter cannot contain quotes or line breaks")
+	}
+	return `"` + s + `"`, nil
+}
+
+// encodeQuoted 按 TE 字符集编码字符串参数并放入双引号，UCS2、HEX 字符集编码后不会出现引号
+func encodeQuoted(charset, s string) (string, error) {
+	text, err := encodeString(charset, s)
+	if err != nil {
+		return "", err
+	}
+	quoted, err := quoteString(text)
+	if err != nil {
+		return "", fmt.Errorf("%w, use UCS2 or HEX charset", err)
+	}
+	return quoted, nil
+}
+
+// decodeString 将 TE 字符集编码的字符串解码为 UTF-8
+func decodeString(charset, s string) (string, error) {
+	switch strings.ToUpper(charset) {
+	case CharsetUCS2:
+		return decodeUCS2Hex(s)
+
+	case CharsetGSM:
+		text, err := gsm7.Decode([]byte(s))
+		if err != nil {
+			return "", err
+		}
+		return string(text), nil
+
+	case CharsetHex:
+		septets, err := hex.DecodeString(s)
+		if err != nil {
+			return "", err
+		}
+		text, err := gsm7.Decode(septets)
+		if err != nil {
+			return "", err
+		}
+		return string(text), nil
+
+	case CharsetLatin1:
+		r := make([]rune, 0, len(s))
+		for i := 0; i < len(s); i++ {
+			r = append(r, rune(s[i]))
+		}
+		return string(r), nil
+	}
+
+	return s, nil
+}
+
+// decodeUCS2Hex 解码 UCS2 十六进制字符串
+func decodeUCS2Hex(s string) (string, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	r, err := ucs2.Decode(b)
+	if err != nil {
+		return "", err
+	}
+	return string(r), nil
+}
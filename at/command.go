@@ -1,8 +1,10 @@
 package at
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // CommandSet 定义可配置的 AT 命令集
@@ -14,6 +16,7 @@ type CommandSet struct {
 	Reset        string // 重置 modem
 	FactoryReset string // 恢复出厂设置
 	SaveSettings string // 保存设置
+	ErrorReport  string // 错误上报模式
 
 	// 信息查询
 	Manufacturer string // 查询制造商
@@ -30,6 +33,9 @@ type CommandSet struct {
 	NetworkRegistration string // 网络注册状态
 	GPRSRegistration    string // GPRS 注册状态
 
+	// 字符集
+	Charset string // TE 字符集
+
 	// 短信相关
 	SMSFormat string // 设置短信格式
 	ListSMS   string // 列出短信
@@ -37,11 +43,35 @@ type CommandSet struct {
 	DeleteSMS string // 删除短信
 	SendSMS   string // 发送短信
 
+	// 电话簿
+	PhonebookStorage string // 选择电话簿存储
+	ReadPhonebook    string // 读取电话簿
+	WritePhonebook   string // 写入电话簿
+	FindPhonebook    string // 查找电话簿
+
+	// SIM 卡
+	PIN            string // 查询锁定状态、输入 PIN/PUK
+	PINRetries     string // 查询密码剩余次数
+	ChangePassword string // 修改密码
+	FacilityLock   string // 设施锁（PIN 锁）
+
+	// 补充业务
+	USSD        string // 非结构化补充业务数据
+	CallForward string // 呼叫转移
+	CallWaiting string // 呼叫等待
+	CLIR        string // 本机号码显示限制
+
 	// 通话相关
-	Dial     string // 拨号
-	Answer   string // 接听
-	Hangup   string // 挂断
-	CallerID string // 来电显示
+	Dial      string // 拨号
+	Answer    string // 接听
+	Hangup    string // 挂断
+	CallerID  string // 来电显示
+	ListCalls string // 当前通话列表
+	DTMF      string // 发送 DTMF 音
+	CallHold  string // 呼叫保持和多方通话
+
+	// 命令超时表，键为命令前缀，按最长前缀匹配；未匹配的命令使用 Config.Timeout
+	Timeouts map[string]time.Duration
 }
 
 // DefaultCommandSet 返回默认的标准 AT 命令集
@@ -54,6 +84,7 @@ func DefaultCommandSet() *CommandSet {
 		Reset:        "ATZ",
 		FactoryReset: "AT&F",
 		SaveSettings: "AT&W",
+		ErrorReport:  "AT+CMEE",
 
 		// 信息查询
 		Manufacturer: "AT+CGMI",
@@ -70,6 +101,9 @@ func DefaultCommandSet() *CommandSet {
 		NetworkRegistration: "AT+CREG",
 		GPRSRegistration:    "AT+CGREG",
 
+		// 字符集
+		Charset: "AT+CSCS",
+
 		// 短信相关
 		SMSFormat: "AT+CMGF",
 		ListSMS:   "AT+CMGL",
@@ -77,60 +111,184 @@ func DefaultCommandSet() *CommandSet {
 		DeleteSMS: "AT+CMGD",
 		SendSMS:   "AT+CMGS",
 
+		// 电话簿
+		PhonebookStorage: "AT+CPBS",
+		ReadPhonebook:    "AT+CPBR",
+		WritePhonebook:   "AT+CPBW",
+		FindPhonebook:    "AT+CPBF",
+
+		// SIM 卡
+		PIN:            "AT+CPIN",
+		PINRetries:     "AT+CPINR",
+		ChangePassword: "AT+CPWD",
+		FacilityLock:   "AT+CLCK",
+
+		// 补充业务
+		USSD:        "AT+CUSD",
+		CallForward: "AT+CCFC",
+		CallWaiting: "AT+CCWA",
+		CLIR:        "AT+CLIR",
+
 		// 通话相关
-		Dial:     "ATD",
-		Answer:   "ATA",
-		Hangup:   "ATH",
-		CallerID: "AT+CLIP",
+		Dial:      "ATD",
+		Answer:    "ATA",
+		Hangup:    "ATH",
+		CallerID:  "AT+CLIP",
+		ListCalls: "AT+CLCC",
+		DTMF:      "AT+VTS",
+		CallHold:  "AT+CHLD",
+
+		// 慢速命令超时
+		Timeouts: map[string]time.Duration{
+			"AT+COPS=?": 180 * time.Second, // 网络扫描
+			"AT+COPS=":  120 * time.Second, // 手动选网/注册
+			"AT+CMGS":   60 * time.Second,  // 发送短信
+			"AT+CMSS":   60 * time.Second,  // 发送存储的短信
+			"AT+CMGL":   30 * time.Second,  // 列出短信
+			"AT+CPBR":   30 * time.Second,  // 读取电话簿
+			"AT+CPBF":   30 * time.Second,  // 查找电话簿
+			"AT+CUSD":   30 * time.Second,  // USSD 请求及网络响应
+			"AT+CCFC":   30 * time.Second,  // 呼叫转移（需网络确认）
+			"AT+CCWA":   30 * time.Second,  // 呼叫等待（需网络确认）
+			"AT+CLIR?":  30 * time.Second,  // 查询号码显示限制签约状态
+			"AT+CPIN=":  15 * time.Second,  // 输入 PIN/PUK
+			"AT+CPWD":   15 * time.Second,  // 修改密码
+			"AT+CLCK":   15 * time.Second,  // 设施锁
+			"ATD":       60 * time.Second,  // 拨号
+			"ATA":       30 * time.Second,  // 接听
+			"AT+VTS":    5 * time.Second,   // 发送 DTMF 音（另加音长）
+			"AT+CHLD":   15 * time.Second,  // 呼叫保持和多方通话
+			"AT+CGATT":  140 * time.Second, // 附着/去附着
+			"AT+CGACT":  150 * time.Second, // 激活/去激活 PDP 上下文
+		},
 	}
 }
 
+// Timeout 返回命令对应的超时时间，未在超时表中声明时返回 0
+func (cs *CommandSet) Timeout(cmd string) time.Duration {
+	cmd = strings.ToUpper(strings.TrimRight(cmd, strings.Join(Terminators, "")))
+
+	timeout, matched := time.Duration(0), 0
+	for prefix, d := range cs.Timeouts {
+		if len(prefix) > matched && strings.HasPrefix(cmd, strings.ToUpper(prefix)) {
+			timeout, matched = d, len(prefix)
+		}
+	}
+	return timeout
+}
+
 // ===== 基本命令 =====
 
 // Test 测试连接
 func (m *Device) Test() error {
-	return m.SendCommandExpect(m.commands.Test, "OK")
+	return m.TestContext(context.Background())
+}
+
+// TestContext 测试连接
+func (m *Device) TestContext(ctx context.Context) error {
+	return m.SendCommandExpectContext(ctx, m.commands.Test, "OK")
+}
+
+// 回显状态
+const (
+	echoUnknown = iota
+	echoOff
+	echoOn
+)
+
+// Echo 返回模块是否开启了命令回显
+//
+// 状态来自 EchoOff/EchoOn 的结果，以及每条命令的响应中是否出现回显，尚未观察到时返回 false。
+func (m *Device) Echo() bool {
+	return m.echo.Load() == echoOn
 }
 
 // EchoOff 关闭回显
 func (m *Device) EchoOff() error {
-	return m.SendCommandExpect(m.commands.EchoOff, "OK")
+	return m.EchoOffContext(context.Background())
+}
+
+// EchoOffContext 关闭回显
+func (m *Device) EchoOffContext(ctx context.Context) error {
+	if err := m.SendCommandExpectContext(ctx, m.commands.EchoOff, "OK"); err != nil {
+		return err
+	}
+	m.echo.Store(echoOff)
+	return nil
 }
 
 // EchoOn 开启回显
 func (m *Device) EchoOn() error {
-	return m.SendCommandExpect(m.commands.EchoOn, "OK")
+	return m.EchoOnContext(context.Background())
+}
+
+// EchoOnContext 开启回显
+func (m *Device) EchoOnContext(ctx context.Context) error {
+	if err := m.SendCommandExpectContext(ctx, m.commands.EchoOn, "OK"); err != nil {
+		return err
+	}
+	m.echo.Store(echoOn)
+	return nil
 }
 
 // Reset 重启模块
 func (m *Device) Reset() error {
-	return m.SendCommandExpect(m.commands.Reset, "OK")
+	return m.ResetContext(context.Background())
+}
+
+// ResetContext 重启模块
+func (m *Device) ResetContext(ctx context.Context) error {
+	return m.SendCommandExpectContext(ctx, m.commands.Reset, "OK")
 }
 
 // FactoryReset 恢复出厂设置
 func (m *Device) FactoryReset() error {
-	return m.SendCommandExpect(m.commands.FactoryReset, "OK")
+	return m.FactoryResetContext(context.Background())
+}
+
+// FactoryResetContext 恢复出厂设置
+func (m *Device) FactoryResetContext(ctx context.Context) error {
+	return m.SendCommandExpectContext(ctx, m.commands.FactoryReset, "OK")
 }
 
 // SaveSettings 保存设置
 func (m *Device) SaveSettings() error {
-	return m.SendCommandExpect(m.commands.SaveSettings, "OK")
+	return m.SaveSettingsContext(context.Background())
+}
+
+// SaveSettingsContext 保存设置
+func (m *Device) SaveSettingsContext(ctx context.Context) error {
+	return m.SendCommandExpectContext(ctx, m.commands.SaveSettings, "OK")
+}
+
+// SetErrorReport 设置错误上报模式，开启后 +CME/+CMS ERROR 携带错误码
+func (m *Device) SetErrorReport(mode ErrorReportMode) error {
+	return m.SetErrorReportContext(context.Background(), mode)
+}
+
+// SetErrorReportContext 设置错误上报模式
+func (m *Device) SetErrorReportContext(ctx context.Context, mode ErrorReportMode) error {
+	cmd := fmt.Sprintf("%s=%d", m.commands.ErrorReport, mode)
+	return m.SendCommandExpectContext(ctx, cmd, "OK")
 }
 
 // ===== 信息查询 =====
 
 // SmpleQuery 通用简单信息查询函数
 func (m *Device) SmpleQuery(cmd string) (string, error) {
-	responses, err := m.SendCommand(cmd)
+	return m.SmpleQueryContext(context.Background(), cmd)
+}
+
+// SmpleQueryContext 通用简单信息查询函数
+func (m *Device) SmpleQueryContext(ctx context.Context, cmd string) (string, error) {
+	info, _, err := m.SendCommandInfoContext(ctx, cmd)
 	if err != nil {
 		return "", err
 	}
 
-	// 查找不以AT开头的行
-	for _, line := range responses {
-		if !strings.HasPrefix(line, "AT") {
-			return line, nil
-		}
+	// 回显已在读取时剥离，第一行信息即为结果
+	if len(info) > 0 {
+		return info[0], nil
 	}
 
 	return "", fmt.Errorf("no info found for %s", cmd)
@@ -138,169 +296,286 @@ func (m *Device) SmpleQuery(cmd string) (string, error) {
 
 // GetManufacturer 查询制造商信息
 func (m *Device) GetManufacturer() (string, error) {
-	return m.SmpleQuery(m.commands.Manufacturer)
+	return m.GetManufacturerContext(context.Background())
+}
+
+// GetManufacturerContext 查询制造商信息
+func (m *Device) GetManufacturerContext(ctx context.Context) (string, error) {
+	return m.SmpleQueryContext(ctx, m.commands.Manufacturer)
 }
 
 // GetModel 查询型号信息
 func (m *Device) GetModel() (string, error) {
-	return m.SmpleQuery(m.commands.Model)
+	return m.GetModelContext(context.Background())
+}
+
+// GetModelContext 查询型号信息
+func (m *Device) GetModelContext(ctx context.Context) (string, error) {
+	return m.SmpleQueryContext(ctx, m.commands.Model)
 }
 
 // GetRevision 查询版本信息
 func (m *Device) GetRevision() (string, error) {
-	return m.SmpleQuery(m.commands.Revision)
+	return m.GetRevisionContext(context.Background())
+}
+
+// GetRevisionContext 查询版本信息
+func (m *Device) GetRevisionContext(ctx context.Context) (string, error) {
+	return m.SmpleQueryContext(ctx, m.commands.Revision)
 }
 
 // GetSerialNumber 查询序列号
 func (m *Device) GetSerialNumber() (string, error) {
-	return m.SmpleQuery(m.commands.SerialNumber)
+	return m.GetSerialNumberContext(context.Background())
+}
+
+// GetSerialNumberContext 查询序列号
+func (m *Device) GetSerialNumberContext(ctx context.Context) (string, error) {
+	return m.SmpleQueryContext(ctx, m.commands.SerialNumber)
 }
 
 // GetIMSI 查询IMSI信息
 func (m *Device) GetIMSI() (string, error) {
-	return m.SmpleQuery(m.commands.IMSI)
+	return m.GetIMSIContext(context.Background())
+}
+
+// GetIMSIContext 查询IMSI信息
+func (m *Device) GetIMSIContext(ctx context.Context) (string, error) {
+	return m.SmpleQueryContext(ctx, m.commands.IMSI)
 }
 
 // GetICCID 查询ICCID信息
 func (m *Device) GetICCID() (string, error) {
-	return m.SmpleQuery(m.commands.ICCID)
+	return m.GetICCIDContext(context.Background())
+}
+
+// GetICCIDContext 查询ICCID信息
+func (m *Device) GetICCIDContext(ctx context.Context) (string, error) {
+	return m.SmpleQueryContext(ctx, m.commands.ICCID)
 }
 
 // GetPhoneNumber 查询手机号
 func (m *Device) GetPhoneNumber() (string, int, error) {
-	responses, err := m.SendCommand(m.commands.PhoneNumber)
+	return m.GetPhoneNumberContext(context.Background())
+}
+
+// GetPhoneNumberContext 查询手机号
+func (m *Device) GetPhoneNumberContext(ctx context.Context) (string, int, error) {
+	resp, err := m.SendCommandResponseContext(ctx, m.commands.PhoneNumber)
 	if err != nil {
 		return "", 0, err
 	}
 
-	for _, line := range responses {
-		label, param := parseParam(line)
-		// 格式: +CNUM: ,"+8613800138000",129
-		if label == "+CNUM" && len(param) >= 2 {
-			number := param[1]
-			tags := parseInt(param[2]) //号码属性
-			return number, tags, nil
-		}
+	// 格式: +CNUM: ,"+8613800138000",129
+	if param, ok := resp.Params("+CNUM"); ok && len(param) >= 2 {
+		tags, _ := param.Int(2) // 号码属性
+		return param.String(1), tags, nil
 	}
 
 	return "", 0, fmt.Errorf("no phone number found")
 }
 
 // GetOperator 查询运营商信息
-func (m *Device) GetOperator() (int, int, string, int, error) {
-	responses, err := m.SendCommand(m.commands.Operator + "?")
+func (m *Device) GetOperator() (Operator, error) {
+	return m.GetOperatorContext(context.Background())
+}
+
+// GetOperatorContext 查询运营商信息
+func (m *Device) GetOperatorContext(ctx context.Context) (Operator, error) {
+	resp, err := m.SendCommandResponseContext(ctx, m.commands.Operator+"?")
 	if err != nil {
-		return 0, 0, "", 0, err
+		return Operator{}, err
 	}
 
-	for _, line := range responses {
-		label, param := parseParam(line)
-		// 格式: +COPS: 0,2,"46001",7
-		if label == "+COPS" && len(param) >= 3 {
-			mode := parseInt(param[0])
-			format := parseInt(param[1])
-			oper := param[2]          // 运营商
-			act := parseInt(param[3]) // 接入技术
-			return mode, format, oper, act, nil
+	// 格式: +COPS: 0,2,"46001",7（未注册时仅有 <mode>）
+	if param, ok := resp.Params("+COPS"); ok && len(param) >= 1 {
+		mode, _ := param.Int(0)
+		format, _ := param.Int(1)
+		oper := Operator{
+			Mode:       OperatorMode(mode),
+			Format:     OperatorFormat(format),
+			Name:       param.String(2),
+			AccessTech: AccessTechUnknown,
 		}
+		if act, ok := param.Int(3); ok {
+			oper.AccessTech = AccessTech(act)
+		}
+		return oper, nil
 	}
 
-	return 0, 0, "", 0, fmt.Errorf("failed to parse operator info")
+	return Operator{}, fmt.Errorf("failed to parse operator info")
+}
+
+// ListOperators 扫描可用运营商，耗时较长（默认超时 180 秒）
+func (m *Device) ListOperators() ([]OperatorInfo, error) {
+	return m.ListOperatorsContext(context.Background())
+}
+
+// ListOperatorsContext 扫描可用运营商
+func (m *Device) ListOperatorsContext(ctx context.Context) ([]OperatorInfo, error) {
+	resp, err := m.SendCommandResponseContext(ctx, m.commands.Operator+"=?")
+	if err != nil {
+		return nil, err
+	}
+
+	// 格式: +COPS: (2,"CHINA MOBILE","CMCC","46000",7),(1,"CHN-UNICOM","UNICOM","46001",2),,(0-4),(0-2)
+	values, ok := resp.Values("+COPS")
+	if !ok {
+		return nil, fmt.Errorf("failed to parse operator list")
+	}
+
+	list := []OperatorInfo{}
+	for _, v := range values {
+		// 运营商列表之后是空参数和支持的 <mode>、<format> 范围
+		if v.Kind != ValueList || len(v.Items) < 4 || v.Items[1].Kind != ValueString {
+			continue
+		}
+		stat, _ := v.Items[0].Int()
+		info := OperatorInfo{
+			Stat:       OperatorStat(stat),
+			Long:       v.Items[1].String(),
+			Short:      v.Items[2].String(),
+			Numeric:    v.Items[3].String(),
+			AccessTech: AccessTechUnknown,
+		}
+		if len(v.Items) > 4 {
+			if act, ok := v.Items[4].Int(); ok {
+				info.AccessTech = AccessTech(act)
+			}
+		}
+		list = append(list, info)
+	}
+	return list, nil
 }
 
 // ===== 网络信号 =====
 
 // GetSignalQuality 查询信号质量
-func (m *Device) GetSignalQuality() (int, int, error) {
-	responses, err := m.SendCommand(m.commands.SignalQuality)
+func (m *Device) GetSignalQuality() (SignalQuality, error) {
+	return m.GetSignalQualityContext(context.Background())
+}
+
+// GetSignalQualityContext 查询信号质量
+func (m *Device) GetSignalQualityContext(ctx context.Context) (SignalQuality, error) {
+	resp, err := m.SendCommandResponseContext(ctx, m.commands.SignalQuality)
 	if err != nil {
-		return 0, 0, err
+		return SignalQuality{}, err
 	}
 
-	for _, line := range responses {
-		label, param := parseParam(line)
-		// 格式: +CSQ: 15,0
-		if label == "+CSQ" && len(param) >= 2 {
-			rssi := parseInt(param[0])
-			ber := parseInt(param[1])
-			return rssi, ber, nil
-		}
+	// 格式: +CSQ: 15,0
+	if param, ok := resp.Params("+CSQ"); ok && len(param) >= 2 {
+		rssi, _ := param.Int(0)
+		ber, _ := param.Int(1)
+		return newSignalQuality(rssi, ber), nil
 	}
 
-	return 0, 0, fmt.Errorf("failed to parse signal quality")
+	return SignalQuality{}, fmt.Errorf("failed to parse signal quality")
 }
 
 // GetNetworkStatus 查询网络注册状态
-func (m *Device) GetNetworkStatus() (int, int, error) {
-	responses, err := m.SendCommand(m.commands.NetworkRegistration + "?")
+func (m *Device) GetNetworkStatus() (Registration, error) {
+	return m.GetNetworkStatusContext(context.Background())
+}
+
+// GetNetworkStatusContext 查询网络注册状态
+func (m *Device) GetNetworkStatusContext(ctx context.Context) (Registration, error) {
+	resp, err := m.SendCommandResponseContext(ctx, m.commands.NetworkRegistration+"?")
 	if err != nil {
-		return 0, 0, err
+		return Registration{}, err
 	}
 
-	for _, line := range responses {
-		label, param := parseParam(line)
-		// 格式: +CREG: 0,1
-		if label == "+CREG" && len(param) >= 2 {
-			n := parseInt(param[0])
-			stat := parseInt(param[1])
-			return n, stat, nil
-		}
+	// 格式: +CREG: 2,1,"1A2B","0C3D5E",7
+	if param, ok := resp.Params("+CREG"); ok && len(param) >= 2 {
+		return parseRegistration(param.Map(), true), nil
 	}
 
-	return 0, 0, fmt.Errorf("failed to parse network status")
+	return Registration{}, fmt.Errorf("failed to parse network status")
 }
 
 // GetGPRSStatus 查询GPRS注册状态
-func (m *Device) GetGPRSStatus() (int, int, error) {
-	responses, err := m.SendCommand(m.commands.GPRSRegistration + "?")
+func (m *Device) GetGPRSStatus() (Registration, error) {
+	return m.GetGPRSStatusContext(context.Background())
+}
+
+// GetGPRSStatusContext 查询GPRS注册状态
+func (m *Device) GetGPRSStatusContext(ctx context.Context) (Registration, error) {
+	resp, err := m.SendCommandResponseContext(ctx, m.commands.GPRSRegistration+"?")
 	if err != nil {
-		return 0, 0, err
+		return Registration{}, err
 	}
 
-	for _, line := range responses {
-		label, param := parseParam(line)
-		// 格式: +CGREG: 0,1
-		if label == "+CGREG" && len(param) >= 2 {
-			n := parseInt(param[0])
-			stat := parseInt(param[1])
-			return n, stat, nil
-		}
+	// 格式: +CGREG: 0,1
+	if param, ok := resp.Params("+CGREG"); ok && len(param) >= 2 {
+		return parseRegistration(param.Map(), true), nil
 	}
 
-	return 0, 0, fmt.Errorf("failed to parse GPRS status")
+	return Registration{}, fmt.Errorf("failed to parse GPRS status")
 }
 
 // ===== 通话相关 =====
 
-// Dial 拨打电话
+// Dial 拨打语音电话，数据电话使用 DialData
 func (m *Device) Dial(number string) error {
-	return m.SendCommandExpect(m.commands.Dial+number, "OK")
+	return m.DialContext(context.Background(), number)
+}
+
+// DialContext 拨打语音电话
+func (m *Device) DialContext(ctx context.Context, number string) error {
+	// 号码后的 ";" 表示语音呼叫，否则模块按数据呼叫处理
+	cmd := m.commands.Dial + strings.TrimSuffix(number, ";") + ";"
+	if err := m.SendCommandExpectContext(ctx, cmd, "OK"); err != nil {
+		m.endCall(err)
+		return err
+	}
+	m.setCall(CallDialing, strings.TrimSuffix(number, ";"), "")
+	return nil
 }
 
 // Answer 接听电话
 func (m *Device) Answer() error {
-	return m.SendCommandExpect(m.commands.Answer, "OK")
+	return m.AnswerContext(context.Background())
+}
+
+// AnswerContext 接听电话
+func (m *Device) AnswerContext(ctx context.Context) error {
+	if err := m.SendCommandExpectContext(ctx, m.commands.Answer, "OK"); err != nil {
+		m.endCall(err)
+		return err
+	}
+	m.setCall(CallActive, "", "")
+	return nil
 }
 
 // Hangup 挂断电话
 func (m *Device) Hangup() error {
-	return m.SendCommandExpect(m.commands.Hangup, "OK")
+	return m.HangupContext(context.Background())
+}
+
+// HangupContext 挂断电话
+func (m *Device) HangupContext(ctx context.Context) error {
+	if err := m.SendCommandExpectContext(ctx, m.commands.Hangup, "OK"); err != nil {
+		return err
+	}
+	m.setCall(CallIdle, "", "")
+	return nil
 }
 
 // GetCallerID 获取来电显示状态
 func (m *Device) GetCallerID() (bool, error) {
-	responses, err := m.SendCommand(m.commands.CallerID + "?")
+	return m.GetCallerIDContext(context.Background())
+}
+
+// GetCallerIDContext 获取来电显示状态
+func (m *Device) GetCallerIDContext(ctx context.Context) (bool, error) {
+	resp, err := m.SendCommandResponseContext(ctx, m.commands.CallerID+"?")
 	if err != nil {
 		return false, err
 	}
 
-	for _, line := range responses {
-		label, param := parseParam(line)
-		// 格式: +CLIP: 1
-		if label == "+CLIP" && len(param) >= 1 {
-			status := parseInt(param[0])
-			return status == 1, nil
-		}
+	// 格式: +CLIP: 1,1
+	if param, ok := resp.Params("+CLIP"); ok && len(param) >= 1 {
+		status, _ := param.Int(0)
+		return status == 1, nil
 	}
 
 	return false, fmt.Errorf("failed to parse caller ID status")
@@ -308,11 +583,16 @@ func (m *Device) GetCallerID() (bool, error) {
 
 // SetCallerID 设置来电显示
 func (m *Device) SetCallerID(enable bool) error {
+	return m.SetCallerIDContext(context.Background(), enable)
+}
+
+// SetCallerIDContext 设置来电显示
+func (m *Device) SetCallerIDContext(ctx context.Context, enable bool) error {
 	cmd := m.commands.CallerID
 	if enable {
 		cmd += "=1"
 	} else {
 		cmd += "=0"
 	}
-	return m.SendCommandExpect(cmd, "OK")
+	return m.SendCommandExpectContext(ctx, cmd, "OK")
 }
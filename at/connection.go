@@ -0,0 +1,184 @@
+package at
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// EventConnection 连接状态事件类型，由设备自身产生，不对应串口上的通知
+const EventConnection = "CONNECTION"
+
+// ConnectionState 串口连接状态
+type ConnectionState int
+
+const (
+	StateConnected    ConnectionState = iota // 已连接
+	StateDisconnected                        // 串口失效
+	StateReconnecting                        // 正在重新打开串口
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	case StateReconnecting:
+		return "reconnecting"
+	}
+	return fmt.Sprintf("unknown(%d)", int(s))
+}
+
+// ConnectionEvent 连接状态变化
+type ConnectionEvent struct {
+	State   ConnectionState `json:"state"`   // 新状态
+	Attempt int             `json:"attempt"` // 重连尝试次数，仅重连时有效
+	Error   string          `json:"error"`   // 导致状态变化的错误，或初始化脚本的错误
+}
+
+// State 返回当前连接状态
+func (m *Device) State() ConnectionState {
+	return ConnectionState(m.state.Load())
+}
+
+// Init 按 Config.SIMPIN 解锁 SIM 卡，然后依次执行 Config.InitScript 中的初始化命令
+func (m *Device) Init() error {
+	return m.InitContext(context.Background())
+}
+
+// InitContext 解锁 SIM 卡并依次执行初始化命令，单条命令失败不影响后续命令，返回第一个错误
+func (m *Device) InitContext(ctx context.Context) error {
+	var first error
+	if err := m.unlockSIM(ctx); err != nil {
+		m.printf("unlock SIM error: %v", err)
+		first = fmt.Errorf("unlock SIM: %w", err)
+		if ctx.Err() != nil || m.closed.Load() {
+			return first
+		}
+	}
+
+	for _, cmd := range m.initScript {
+		if _, err := m.SendCommandContext(ctx, cmd); err != nil {
+			m.printf("init %s error: %v", cmd, err)
+			if first == nil {
+				first = fmt.Errorf("init %s: %w", cmd, err)
+			}
+			if ctx.Err() != nil || m.closed.Load() {
+				return first
+			}
+		}
+	}
+	return first
+}
+
+// setState 更新连接状态并发布连接事件
+func (m *Device) setState(state ConnectionState, attempt int, err error) {
+	m.state.Store(int32(state))
+
+	data := ConnectionEvent{State: state, Attempt: attempt}
+	if err != nil {
+		data.Error = err.Error()
+	}
+	m.dispatch(Event{
+		Kind: EventConnection,
+		Line: state.String(),
+		Data: data,
+		Time: time.Now(),
+	})
+}
+
+// getPort 返回当前串口，串口失效且尚未重新打开时返回 nil
+func (m *Device) getPort() Port {
+	m.portMu.RLock()
+	defer m.portMu.RUnlock()
+	return m.port
+}
+
+// setPort 替换当前串口，设备已关闭时关闭新串口并返回 false
+func (m *Device) setPort(port Port) bool {
+	m.portMu.Lock()
+	defer m.portMu.Unlock()
+
+	if m.closed.Load() {
+		if port != nil {
+			port.Close()
+		}
+		return false
+	}
+	m.port = port
+	// 新串口上的模块可能已重启，回显状态需重新观察
+	m.echo.Store(echoUnknown)
+	return true
+}
+
+// dropPort 关闭失效的串口
+func (m *Device) dropPort(port Port) {
+	m.setPort(nil)
+	if err := port.Close(); err != nil {
+		m.printf("close port error: %v", err)
+	}
+}
+
+// reconnect 以指数退避重新打开串口，设备关闭时返回 false
+func (m *Device) reconnect() bool {
+	delay := m.reconnectDelay
+	for attempt := 1; ; attempt++ {
+		m.setState(StateReconnecting, attempt, nil)
+
+		port, err := m.portFactory()
+		if err == nil {
+			if !m.setPort(port) {
+				return false
+			}
+			go m.recoverSession()
+			return true
+		}
+		m.printf("reconnect attempt %d error: %v", attempt, err)
+
+		select {
+		case <-m.done:
+			return false
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, m.reconnectMaxDelay)
+	}
+}
+
+// recoverSession 串口重新打开后执行初始化脚本，并恢复此前设置的字符集和短信模式
+//
+// 在读取循环之外执行，避免等待响应时阻塞读取。
+//
+// 各步骤互不影响，任一步骤失败仍继续恢复其余设置，连接事件只记录第一个错误。
+func (m *Device) recoverSession() {
+	ctx := context.Background()
+	first := m.InitContext(ctx)
+
+	if charset := m.currentCharset(); charset != "" {
+		if err := m.SetCharsetContext(ctx, charset); err != nil {
+			m.printf("restore charset error: %v", err)
+			if first == nil {
+				first = fmt.Errorf("restore charset: %w", err)
+			}
+		}
+	}
+	if mode := int(m.smsMode.Load()); mode != 0 {
+		if err := m.SetSMSModeContext(ctx, mode); err != nil {
+			m.printf("restore sms mode error: %v", err)
+			if first == nil {
+				first = fmt.Errorf("restore sms mode: %w", err)
+			}
+		}
+	}
+
+	if m.closed.Load() {
+		return
+	}
+	m.setState(StateConnected, 0, first)
+}
+
+// isTransient 判断读取错误是否只表示暂无数据（读取超时），io.EOF 表示对端挂断
+func isTransient(err error) bool {
+	t, ok := err.(interface{ Timeout() bool })
+	return ok && t.Timeout()
+}
@@ -0,0 +1,118 @@
+package at_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rehiy/modem/at"
+	"github.com/rehiy/modem/port/sim"
+)
+
+func TestRecoverSession(t *testing.T) {
+	second := sim.New(&sim.Config{Echo: true, ReadTimeout: 10 * time.Millisecond})
+	second.Handle(`AT\+CSCS=.*`, "OK")
+
+	opened := false
+	d, first := newSimDevice(t, &at.Config{
+		// 初始化脚本在新串口上失败，不影响恢复字符集和短信模式
+		InitScript:     []string{"AT+BAD"},
+		ReconnectDelay: 10 * time.Millisecond,
+		PortFactory: func() (at.Port, error) {
+			if opened {
+				return nil, errors.New("no port")
+			}
+			opened = true
+			return second, nil
+		},
+	})
+	t.Cleanup(func() { second.Close() })
+	first.Handle(`AT\+CSCS=.*`, "OK")
+
+	if err := d.SetCharset("UCS2"); err != nil {
+		t.Fatalf("SetCharset: %v", err)
+	}
+	if err := d.SetSMSMode(1); err != nil {
+		t.Fatalf("SetSMSMode: %v", err)
+	}
+
+	events, cancel := d.Subscribe(at.EventConnection)
+	defer cancel()
+	first.Disconnect()
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			data := event.Data.(at.ConnectionEvent)
+			if data.State != at.StateConnected {
+				continue
+			}
+			if !strings.Contains(data.Error, "AT+BAD") {
+				t.Fatalf("connection error = %q, want init error", data.Error)
+			}
+			history := strings.Join(second.History(), "\n")
+			if !strings.Contains(history, `AT+CSCS="UCS2"`) || !strings.Contains(history, "AT+CMGF=1") {
+				t.Fatalf("history = %q", second.History())
+			}
+			return
+		case <-timeout:
+			t.Fatal("not reconnected")
+		}
+	}
+}
+
+// eofPort 挂断后读取返回 io.EOF 的串口
+type eofPort struct {
+	*sim.Modem
+	hangup *atomic.Bool
+}
+
+func (p eofPort) Read(buf []byte) (int, error) {
+	if p.hangup.Load() {
+		return 0, io.EOF
+	}
+	return p.Modem.Read(buf)
+}
+
+func TestReconnectOnEOF(t *testing.T) {
+	first := eofPort{sim.New(&sim.Config{Echo: true, ReadTimeout: 10 * time.Millisecond}), &atomic.Bool{}}
+	second := sim.New(&sim.Config{Echo: true, ReadTimeout: 10 * time.Millisecond})
+	t.Cleanup(func() {
+		first.Close()
+		second.Close()
+	})
+
+	d := newDevice(t, first, nil, &at.Config{
+		Timeout:        200 * time.Millisecond,
+		ReconnectDelay: 10 * time.Millisecond,
+		PortFactory:    func() (at.Port, error) { return second, nil },
+	})
+	events, cancel := d.Subscribe(at.EventConnection)
+	defer cancel()
+
+	// io.EOF 表示对端挂断，而不是暂无数据
+	first.hangup.Store(true)
+	select {
+	case event := <-events:
+		if data := event.Data.(at.ConnectionEvent); data.State != at.StateDisconnected || !strings.Contains(data.Error, "EOF") {
+			t.Fatalf("event = %+v", data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("EOF not treated as disconnect")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for d.State() != at.StateConnected {
+		if time.Now().After(deadline) {
+			t.Fatalf("State = %v, want connected", d.State())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := d.Test(); err != nil {
+		t.Fatalf("command after reconnect: %v", err)
+	}
+}
@@ -0,0 +1,295 @@
+package at
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// escapeSequence 退出数据模式的转义序列，退出期间作为当前命令名
+const escapeSequence = "+++"
+
+// dtrSetter 支持控制 DTR 信号的串口，如 port/serial 和 port/rfc2217
+type dtrSetter interface {
+	SetDTR(on bool) error
+}
+
+// DataConn 数据模式连接，在 CONNECT 之后透传串口上的原始数据，用于 PPP 拨号或透明传输
+//
+// 数据模式期间读取循环不再按行分发，其他命令返回 ErrDataMode；
+// 收到 NO CARRIER 时 Read 返回 io.EOF，设备自动回到命令模式。
+type DataConn struct {
+	m       *Device
+	connect string        // CONNECT 结果码，可能带有速率，如 "CONNECT 115200"
+	data    chan []byte   // 读取循环收到的原始数据
+	rest    []byte        // 上次未读完的数据
+	tail    []byte        // 上次数据的末尾，用于识别跨越两次读取的 NO CARRIER
+	online  chan struct{} // 收到 CONNECT 时关闭
+	done    chan struct{} // 数据模式结束时关闭
+	once    sync.Once
+	err     error       // 数据模式结束的原因，done 关闭后有效
+	escape  atomic.Bool // 正在退出数据模式，等待模块确认
+}
+
+// EnterDataMode 发送拨号等命令（如 ATD*99#、AT+CIPMODE 透传连接），收到 CONNECT 后进入数据模式
+//
+// 命令返回 CONNECT 以外的最终响应时返回错误，设备保持命令模式。
+func (m *Device) EnterDataMode(cmd string) (*DataConn, error) {
+	return m.EnterDataModeContext(context.Background(), cmd)
+}
+
+// EnterDataModeContext 发送命令并在收到 CONNECT 后进入数据模式，支持通过 ctx 取消
+//
+// 命令发出后被取消时，模块若仍以 CONNECT 应答，则在后台转义回命令模式，连接不挂断（可通过 ATO 恢复）。
+func (m *Device) EnterDataModeContext(ctx context.Context, cmd string) (*DataConn, error) {
+	if err := m.acquire(ctx); err != nil {
+		return nil, err
+	}
+
+	// 写入命令前登记连接，读取循环在 CONNECT 行之后立即切换为原始数据
+	c := &DataConn{
+		m:      m,
+		data:   make(chan []byte, 64),
+		online: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	m.data.Store(c)
+
+	responses, detached, err := m.exchange(ctx, cmd, cmd, m.commandTimeout(cmd))
+	if err == nil && !strings.HasPrefix(responses[len(responses)-1], m.responses.Connect) {
+		err = fmt.Errorf("expected response %q not found in %v", m.responses.Connect, responses)
+	}
+	if detached {
+		// 连接保持登记，由后台读完最终响应后处理
+		return nil, err
+	}
+	if err != nil {
+		c.finish(err)
+		m.release()
+		return nil, err
+	}
+
+	c.connect = responses[len(responses)-1]
+	m.printf("data mode: %s", c.connect)
+	m.release()
+	return c, nil
+}
+
+// Connect 返回 CONNECT 结果码，如 "CONNECT 115200"
+func (c *DataConn) Connect() string {
+	return c.connect
+}
+
+// Done 返回数据模式结束时关闭的通道
+func (c *DataConn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Read 读取原始数据，载波丢失（NO CARRIER）后返回 io.EOF
+func (c *DataConn) Read(buf []byte) (int, error) {
+	if len(c.rest) > 0 {
+		n := copy(buf, c.rest)
+		c.rest = c.rest[n:]
+		return n, nil
+	}
+
+	select {
+	case data := <-c.data:
+		return c.take(buf, data), nil
+	case <-c.done:
+		// 结束前收到的数据仍然返回
+		select {
+		case data := <-c.data:
+			return c.take(buf, data), nil
+		default:
+			return 0, c.err
+		}
+	case <-c.m.done:
+		return 0, ErrClosed
+	}
+}
+
+// take 复制数据，保留未读完的部分
+func (c *DataConn) take(buf, data []byte) int {
+	n := copy(buf, data)
+	c.rest = data[n:]
+	return n
+}
+
+// Write 写入原始数据
+func (c *DataConn) Write(data []byte) (int, error) {
+	select {
+	case <-c.done:
+		return 0, ErrClosed
+	default:
+	}
+
+	port := c.m.getPort()
+	if port == nil {
+		return 0, ErrDisconnected
+	}
+	return port.Write(data)
+}
+
+// Close 退出数据模式，回到命令模式
+//
+// 默认在静默时间前后发送 "+++"，Config.EscapeDTR 为 true 时改为拉低 DTR；
+// 退出后连接是否保持由模块决定（AT&D 设置），挂断需另行发送 ATH。
+// 模块未确认时返回错误，连接仍处于数据模式，可以继续读写或再次 Close。
+func (c *DataConn) Close() error {
+	select {
+	case <-c.done:
+		return nil
+	default:
+	}
+	return c.m.leaveDataMode(c)
+}
+
+// finish 结束数据模式，读取循环回到按行分发
+func (c *DataConn) finish(err error) {
+	c.once.Do(func() {
+		c.err = err
+		c.m.data.CompareAndSwap(c, nil)
+		close(c.done)
+	})
+}
+
+// goOnline 收到 CONNECT，之后的数据按原始数据处理
+func (c *DataConn) goOnline() {
+	select {
+	case <-c.online:
+	default:
+		close(c.online)
+	}
+}
+
+// isOnline 返回是否已收到 CONNECT
+func (c *DataConn) isOnline() bool {
+	select {
+	case <-c.online:
+		return true
+	default:
+		return false
+	}
+}
+
+// feed 投递读取循环收到的原始数据，遇到 NO CARRIER 或退出时的确认时结束数据模式，返回其后按行处理的剩余数据
+func (c *DataConn) feed(data []byte) []byte {
+	markers := [][]byte{[]byte("\r\n" + c.m.responses.NoCarrier)}
+	if c.escape.Load() {
+		markers = append(markers, []byte("\r\n"+c.m.responses.OK+"\r\n"))
+	}
+	rest := []byte(nil)
+
+	combined := append(append([]byte{}, c.tail...), data...)
+	index, keep := -1, 0
+	for _, marker := range markers {
+		if i := bytes.Index(combined, marker); i >= 0 && (index < 0 || i < index) {
+			index = i
+		}
+		keep = max(keep, len(marker)-1)
+	}
+	if index >= 0 {
+		// 上次已投递的末尾不再重复投递
+		data = data[:max(0, index-len(c.tail))]
+		rest = combined[index:]
+	}
+
+	if len(data) > 0 {
+		chunk := append([]byte{}, data...)
+		select {
+		case c.data <- chunk:
+		case <-c.done:
+		case <-c.m.done:
+		}
+	}
+
+	if rest != nil {
+		if c.escape.Load() {
+			c.finish(ErrClosed)
+		} else {
+			c.m.printf("data mode: carrier lost")
+			c.finish(io.EOF)
+		}
+		return rest
+	}
+
+	if len(combined) > keep {
+		combined = combined[len(combined)-keep:]
+	}
+	c.tail = append(c.tail[:0], combined...)
+	return nil
+}
+
+// dataConn 返回已进入数据模式的连接
+func (m *Device) dataConn() *DataConn {
+	if c := m.data.Load(); c != nil && c.isOnline() {
+		return c
+	}
+	return nil
+}
+
+// leaveDataMode 获取命令锁后退出数据模式
+func (m *Device) leaveDataMode(c *DataConn) error {
+	select {
+	case m.lock <- struct{}{}:
+	case <-m.done:
+		return ErrClosed
+	}
+	defer m.release()
+
+	return m.escapeDataMode(c)
+}
+
+// escapeDataMode 通过 "+++" 或 DTR 回到命令模式并等待模块确认，调用方需持有命令锁
+//
+// 确认（OK 或 NO CARRIER）到达前数据仍原样透传，之后读取循环才回到按行分发；
+// 拉低 DTR 时模块可能以 NO CARRIER 应答，需作为最终响应而非通知。
+// 出错时连接保持数据模式。
+func (m *Device) escapeDataMode(c *DataConn) error {
+	for len(m.responseChan) > 0 {
+		<-m.responseChan
+	}
+	m.cmd.Store(escapeSequence)
+	c.escape.Store(true)
+	defer c.escape.Store(false)
+
+	if err := m.writeEscape(); err != nil {
+		return err
+	}
+	if _, err := m.readResponse(context.Background(), m.escapeGuard+m.timeout); err != nil {
+		return err
+	}
+	c.finish(ErrClosed)
+	return nil
+}
+
+// writeEscape 发送转义序列或拉低 DTR
+func (m *Device) writeEscape() error {
+	port := m.getPort()
+	if port == nil {
+		return ErrDisconnected
+	}
+
+	if m.escapeDTR {
+		setter, ok := port.(dtrSetter)
+		if !ok {
+			return fmt.Errorf("port does not support DTR")
+		}
+		m.printf("data mode: dropping DTR")
+		if err := setter.SetDTR(false); err != nil {
+			return err
+		}
+		time.Sleep(m.escapeGuard)
+		return setter.SetDTR(true)
+	}
+
+	// "+++" 前后都需要保持静默，模块才将其识别为转义序列
+	time.Sleep(m.escapeGuard)
+	return m.writeString(escapeSequence)
+}
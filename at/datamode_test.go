@@ -0,0 +1,250 @@
+package at_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rehiy/modem/at"
+	"github.com/rehiy/modem/port/sim"
+)
+
+// dtrModem 支持 DTR 的模拟器，拉低 DTR 时以 NO CARRIER 应答
+type dtrModem struct {
+	*sim.Modem
+}
+
+func (s dtrModem) SetDTR(on bool) error {
+	if !on {
+		s.URC("NO CARRIER")
+	}
+	return nil
+}
+
+// escapeModem 识别 "+++" 的模拟器，reply 为空时不应答
+type escapeModem struct {
+	*sim.Modem
+	reply   *atomic.Value
+	escapes *atomic.Int32 // 收到 "+++" 的次数
+}
+
+func newEscapeModem(s *sim.Modem, reply string) escapeModem {
+	e := escapeModem{Modem: s, reply: &atomic.Value{}, escapes: &atomic.Int32{}}
+	e.reply.Store(reply)
+	return e
+}
+
+func (s escapeModem) Write(data []byte) (int, error) {
+	if string(data) == "+++" {
+		s.escapes.Add(1)
+		if reply := s.reply.Load().(string); reply != "" {
+			s.URC(reply)
+		}
+		return len(data), nil
+	}
+	return s.Modem.Write(data)
+}
+
+// failingDTR 无法设置 DTR 的串口
+type failingDTR struct {
+	*sim.Modem
+}
+
+func (failingDTR) SetDTR(on bool) error {
+	return errors.New("dtr failed")
+}
+
+func TestDataModeReadAndCarrierLost(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`ATD\*99#`, "CONNECT 115200")
+
+	conn, err := d.EnterDataMode("ATD*99#")
+	if err != nil {
+		t.Fatalf("EnterDataMode: %v", err)
+	}
+	if conn.Connect() != "CONNECT 115200" {
+		t.Fatalf("Connect = %q", conn.Connect())
+	}
+
+	s.Inject("\x7e\x01\x02")
+	buf := make([]byte, 16)
+	n, err := conn.Read(buf)
+	if err != nil || string(buf[:n]) != "\x7e\x01\x02" {
+		t.Fatalf("Read = %q, %v", buf[:n], err)
+	}
+
+	s.URC("NO CARRIER")
+	if _, err := conn.Read(buf); err != io.EOF {
+		t.Fatalf("Read after carrier lost = %v, want io.EOF", err)
+	}
+	if err := d.Test(); err != nil {
+		t.Fatalf("command after data mode: %v", err)
+	}
+}
+
+func TestDataModeCommandFails(t *testing.T) {
+	d, s := newSimDevice(t, &at.Config{Timeout: 5 * time.Second})
+	s.Handle(`AT\+CIPOPEN=.*`, "NO CARRIER")
+
+	start := time.Now()
+	_, err := d.EnterDataMode(`AT+CIPOPEN=0,"TCP","example.com",80`)
+	if !errors.Is(err, at.ErrNoCarrier) {
+		t.Fatalf("EnterDataMode = %v, want ErrNoCarrier", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("EnterDataMode took %v, want immediate failure", time.Since(start))
+	}
+}
+
+func TestDataModeCloseWithDTR(t *testing.T) {
+	s := sim.New(&sim.Config{ReadTimeout: 10 * time.Millisecond})
+	s.Handle(`ATD\*99#`, "CONNECT")
+	t.Cleanup(func() { s.Close() })
+	d := newDevice(t, dtrModem{s}, nil, &at.Config{
+		Timeout:     200 * time.Millisecond,
+		EscapeGuard: 10 * time.Millisecond,
+		EscapeDTR:   true,
+	})
+
+	conn, err := d.EnterDataMode("ATD*99#")
+	if err != nil {
+		t.Fatalf("EnterDataMode: %v", err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := d.Test(); err != nil {
+		t.Fatalf("command after data mode: %v", err)
+	}
+}
+
+func TestDataModeEscapeKeepsRawData(t *testing.T) {
+	s := sim.New(&sim.Config{Echo: true, ReadTimeout: 10 * time.Millisecond})
+	s.Handle(`ATD\*99#`, "CONNECT")
+	t.Cleanup(func() { s.Close() })
+	d := newDevice(t, newEscapeModem(s, "OK"), nil, &at.Config{
+		Timeout:     200 * time.Millisecond,
+		EscapeGuard: 50 * time.Millisecond,
+	})
+	events, cancel := d.Subscribe()
+	defer cancel()
+
+	conn, err := d.EnterDataMode("ATD*99#")
+	if err != nil {
+		t.Fatalf("EnterDataMode: %v", err)
+	}
+
+	// 静默时间内到达的数据仍作为原始数据投递，不按行解析
+	time.AfterFunc(10*time.Millisecond, func() { s.Inject("+CMTI: \"SM\",1\r\n") })
+	if err := conn.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	if err != nil || string(buf[:n]) != "+CMTI: \"SM\",1\r\n" {
+		t.Fatalf("Read = %q, %v", buf[:n], err)
+	}
+	if _, err := conn.Read(buf); !errors.Is(err, at.ErrClosed) {
+		t.Fatalf("Read after Close = %v, want ErrClosed", err)
+	}
+	select {
+	case event := <-events:
+		t.Fatalf("raw data dispatched as %+v", event)
+	default:
+	}
+
+	if err := d.Test(); err != nil {
+		t.Fatalf("command after data mode: %v", err)
+	}
+}
+
+func TestDataModeEscapeFails(t *testing.T) {
+	s := sim.New(&sim.Config{Echo: true, ReadTimeout: 10 * time.Millisecond})
+	s.Handle(`ATD\*99#`, "CONNECT")
+	t.Cleanup(func() { s.Close() })
+	modem := newEscapeModem(s, "")
+	d := newDevice(t, modem, nil, &at.Config{
+		Timeout:     50 * time.Millisecond,
+		EscapeGuard: 10 * time.Millisecond,
+	})
+
+	conn, err := d.EnterDataMode("ATD*99#")
+	if err != nil {
+		t.Fatalf("EnterDataMode: %v", err)
+	}
+
+	// 模块未确认时保持数据模式，数据仍可读写
+	if err := conn.Close(); !errors.Is(err, at.ErrTimeout) {
+		t.Fatalf("Close = %v, want ErrTimeout", err)
+	}
+	s.Inject("\x7e\x01")
+	buf := make([]byte, 16)
+	if n, err := conn.Read(buf); err != nil || string(buf[:n]) != "\x7e\x01" {
+		t.Fatalf("Read = %q, %v", buf[:n], err)
+	}
+	if err := d.Test(); !errors.Is(err, at.ErrDataMode) {
+		t.Fatalf("command in data mode = %v, want ErrDataMode", err)
+	}
+
+	// 再次退出成功后回到命令模式
+	modem.reply.Store("OK")
+	if err := conn.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := d.Test(); err != nil {
+		t.Fatalf("command after data mode: %v", err)
+	}
+}
+
+func TestDataModeDTRFails(t *testing.T) {
+	s := sim.New(&sim.Config{Echo: true, ReadTimeout: 10 * time.Millisecond})
+	s.Handle(`ATD\*99#`, "CONNECT")
+	t.Cleanup(func() { s.Close() })
+	d := newDevice(t, failingDTR{s}, nil, &at.Config{EscapeGuard: 10 * time.Millisecond, EscapeDTR: true})
+
+	conn, err := d.EnterDataMode("ATD*99#")
+	if err != nil {
+		t.Fatalf("EnterDataMode: %v", err)
+	}
+	if err := conn.Close(); err == nil || err.Error() != "dtr failed" {
+		t.Fatalf("Close = %v, want dtr failed", err)
+	}
+	select {
+	case <-conn.Done():
+		t.Fatal("data mode ended after failed escape")
+	default:
+	}
+	s.Inject("\x7e")
+	buf := make([]byte, 16)
+	if n, err := conn.Read(buf); err != nil || string(buf[:n]) != "\x7e" {
+		t.Fatalf("Read = %q, %v", buf[:n], err)
+	}
+}
+
+func TestDataModeCancelledBeforeConnect(t *testing.T) {
+	s := sim.New(&sim.Config{Echo: true, ReadTimeout: 10 * time.Millisecond})
+	s.AddRule(sim.Rule{Pattern: `ATD\*99#`, Responses: []string{"CONNECT"}, Delay: 100 * time.Millisecond})
+	t.Cleanup(func() { s.Close() })
+	modem := newEscapeModem(s, "OK")
+	d := newDevice(t, modem, nil, &at.Config{
+		Timeout:     200 * time.Millisecond,
+		EscapeGuard: 10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := d.EnterDataModeContext(ctx, "ATD*99#"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("EnterDataModeContext = %v, want DeadlineExceeded", err)
+	}
+
+	// 迟到的 CONNECT 之后先转义回命令模式，下一条命令才能执行
+	if err := d.Test(); err != nil {
+		t.Fatalf("command after cancelled data mode: %v", err)
+	}
+	if n := modem.escapes.Load(); n != 1 {
+		t.Fatalf("escapes = %d, want 1", n)
+	}
+}
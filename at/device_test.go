@@ -0,0 +1,249 @@
+package at_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehiy/modem/at"
+	"github.com/rehiy/modem/port/sim"
+	"github.com/rehiy/modem/sms"
+	"github.com/rehiy/modem/sms/pdumode"
+	"github.com/rehiy/modem/sms/tpdu"
+)
+
+// deliverPDU 来自 27838890001 的 "hellohello"，带 SMSC 地址
+const deliverPDU = "07917283010010F5040BC87238880900F10000993092516195800AE8329BFD4697D9EC37"
+
+func TestSignalQuality(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`AT\+CSQ`, "+CSQ: 20,99", "OK")
+
+	sq, err := d.GetSignalQuality()
+	if err != nil {
+		t.Fatalf("GetSignalQuality: %v", err)
+	}
+	if !sq.Valid() || sq.RawRSSI != 20 || sq.RSSI != -73 || sq.RawBER != 99 {
+		t.Fatalf("GetSignalQuality = %+v", sq)
+	}
+}
+
+func TestCMEErrorMapping(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`AT\+CPIN\?`, "+CME ERROR: 10")
+	s.Handle(`AT\+CIMI`, "+CME ERROR: SIM not inserted")
+
+	for _, cmd := range []string{"AT+CPIN?", "AT+CIMI"} {
+		_, err := d.SendCommand(cmd)
+		if !errors.Is(err, at.ErrSIMNotInserted) {
+			t.Fatalf("%s: err = %v, want ErrSIMNotInserted", cmd, err)
+		}
+		var e *at.Error
+		if !errors.As(err, &e) || e.Kind != at.ErrorCME || e.Command != cmd {
+			t.Fatalf("%s: err = %#v", cmd, err)
+		}
+	}
+}
+
+func TestEchoDetection(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`AT\+CGMI`, "SIMCOM", "OK")
+
+	lines, final, err := d.SendCommandInfo("AT+CGMI")
+	if err != nil {
+		t.Fatalf("SendCommandInfo: %v", err)
+	}
+	if len(lines) != 1 || lines[0] != "SIMCOM" || final != "OK" {
+		t.Fatalf("SendCommandInfo = %q, %q", lines, final)
+	}
+	if !d.Echo() {
+		t.Fatal("echo not detected")
+	}
+
+	if err := d.EchoOff(); err != nil {
+		t.Fatalf("EchoOff: %v", err)
+	}
+	if d.Echo() {
+		t.Fatal("echo still on after EchoOff")
+	}
+	lines, _, err = d.SendCommandInfo("AT+CGMI")
+	if err != nil || len(lines) != 1 || lines[0] != "SIMCOM" {
+		t.Fatalf("SendCommandInfo = %q, %v", lines, err)
+	}
+}
+
+func TestSendSMSPdu(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+
+	if err := d.SendSMSPdu("+8613800138000", "hello"); err != nil {
+		t.Fatalf("SendSMSPdu: %v", err)
+	}
+	sent := s.SentSMS()
+	if len(sent) != 1 {
+		t.Fatalf("SentSMS = %q", sent)
+	}
+
+	// 提示符后提交的 PDU 以 Ctrl-Z 结束，模拟器记录为 <pdu>
+	history := s.History()
+	n := len(history)
+	if n < 2 || !strings.HasPrefix(history[n-2], "AT+CMGS=") || history[n-1] != "<"+sent[0]+">" {
+		t.Fatalf("History = %q", history)
+	}
+	pdu, err := pdumode.UnmarshalHexString(sent[0])
+	if err != nil {
+		t.Fatalf("submitted pdu: %v", err)
+	}
+	msg, err := sms.Unmarshal(pdu.TPDU, sms.AsMO)
+	if err != nil {
+		t.Fatalf("submitted tpdu: %v", err)
+	}
+	text, err := sms.Decode([]*tpdu.TPDU{msg})
+	if err != nil || msg.DA.Number() != "+8613800138000" || string(text) != "hello" {
+		t.Fatalf("submitted tpdu = %+v, %q, %v", msg, text, err)
+	}
+}
+
+func TestSMSStore(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	index := s.StoreSMS(sim.StatUnread, deliverPDU)
+
+	list, err := d.ListSMSPdu(sim.StatAll)
+	if err != nil {
+		t.Fatalf("ListSMSPdu: %v", err)
+	}
+	if len(list) != 1 || list[0].Index != index || list[0].Text != "hellohello" {
+		t.Fatalf("ListSMSPdu = %+v", list)
+	}
+
+	msg, err := d.ReadSMSPdu(index)
+	if err != nil {
+		t.Fatalf("ReadSMSPdu: %v", err)
+	}
+	if msg.Index != index || msg.TPDU.OA.Number() != "27838890001" {
+		t.Fatalf("ReadSMSPdu = %+v", msg)
+	}
+
+	if err := d.DeleteSMS([]int{index}); err != nil {
+		t.Fatalf("DeleteSMS: %v", err)
+	}
+	list, err = d.ListSMSPdu(sim.StatAll)
+	if err != nil || len(list) != 0 {
+		t.Fatalf("ListSMSPdu after delete = %+v, %v", list, err)
+	}
+}
+
+func TestScheduledURC(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	events, cancel := d.Subscribe("+CMTI")
+	defer cancel()
+
+	s.URCAfter(20*time.Millisecond, `+CMTI: "SM",3`)
+
+	select {
+	case event := <-events:
+		data, ok := event.Data.(at.SMSReadyEvent)
+		if !ok || data.Storage != "SM" || data.Index != 3 {
+			t.Fatalf("event = %+v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("+CMTI not delivered")
+	}
+}
+
+func TestSignalQualityUnknown(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`AT\+CSQ`, "+CSQ: 99,99", "OK")
+
+	sq, err := d.GetSignalQuality()
+	if err != nil {
+		t.Fatalf("GetSignalQuality: %v", err)
+	}
+	if sq.Valid() || sq.RSSI != 0 || sq.BER != -1 {
+		t.Fatalf("GetSignalQuality = %+v", sq)
+	}
+}
+
+func TestTimeoutWithDeadline(t *testing.T) {
+	commands := at.DefaultCommandSet()
+	commands.Timeouts["AT+SLOW"] = 50 * time.Millisecond
+	d, s := newSimDevice(t, &at.Config{CommandSet: commands})
+	s.Ignore(`AT\+SLOW`)
+
+	// ctx 的截止时间晚于超时表时以超时表为准
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	if _, err := d.SendCommandContext(ctx, "AT+SLOW"); !errors.Is(err, at.ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("returned after %v", elapsed)
+	}
+
+	// ctx 的截止时间更早时以 ctx 为准
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := d.SendCommandContext(ctx, "AT+SLOW"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestSendSMSPduCancelledAtPrompt(t *testing.T) {
+	d, s := newSimDevice(t, &at.Config{Timeout: 200 * time.Millisecond})
+	s.AddRule(sim.Rule{
+		Pattern: `AT\+CMGS=\d+`,
+		Prompt:  func(cmd, data string) []string { return []string{"+CMGS: 1", "OK"} },
+		Delay:   100 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := d.SendSMSPduContext(ctx, "+8613800138000", "hello"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("SendSMSPduContext = %v, want DeadlineExceeded", err)
+	}
+
+	// 迟到的提示符之后发送 ESC 取消输入，下一条命令不会被当作短信内容
+	if err := d.Test(); err != nil {
+		t.Fatalf("command after cancelled send: %v", err)
+	}
+	history := s.History()
+	if n := len(history); n < 3 || !strings.HasPrefix(history[n-3], "AT+CMGS=") || history[n-2] != "<ESC>" || history[n-1] != "AT" {
+		t.Fatalf("History = %q", history)
+	}
+	if sent := s.SentSMS(); len(sent) != 0 {
+		t.Fatalf("SentSMS = %q", sent)
+	}
+}
+
+func TestCancelledCommandDrainsLateResponse(t *testing.T) {
+	d, s := newSimDevice(t, &at.Config{Timeout: 200 * time.Millisecond})
+	s.AddRule(sim.Rule{Pattern: `AT\+SLOW`, Responses: []string{"+SLOW: 1", "OK"}, Delay: 100 * time.Millisecond})
+	s.Handle(`AT\+FAST`, "+FAST: 2", "OK")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := d.SendCommandContext(ctx, "AT+SLOW"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+
+	// 迟到的响应读完之前命令锁仍被占用，下一条命令不会写入
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := d.SendCommandContext(ctx, "AT+FAST"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if n := countCommands(s.History(), "AT+FAST"); n != 0 {
+		t.Fatalf("AT+FAST sent %d times while lock held", n)
+	}
+
+	// 锁释放后下一条命令得到自己的响应，而不是残留的响应
+	responses, err := d.SendCommand("AT+FAST")
+	if err != nil {
+		t.Fatalf("SendCommand: %v", err)
+	}
+	if len(responses) != 2 || responses[0] != "+FAST: 2" || responses[1] != "OK" {
+		t.Fatalf("responses = %q", responses)
+	}
+}
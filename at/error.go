@@ -0,0 +1,365 @@
+package at
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrClosed 设备已关闭
+	ErrClosed = errors.New("device closed")
+	// ErrDisconnected 串口已失效，正在等待重新打开
+	ErrDisconnected = errors.New("port disconnected")
+	// ErrTimeout 命令超时
+	ErrTimeout = errors.New("command timeout")
+	// ErrDataMode 设备处于数据模式，需先关闭 DataConn 回到命令模式
+	ErrDataMode = errors.New("device in data mode")
+)
+
+// ErrorKind 错误结果码类型
+type ErrorKind int
+
+const (
+	ErrorGeneric    ErrorKind = iota // ERROR
+	ErrorCME                         // +CME ERROR - 移动设备错误
+	ErrorCMS                         // +CMS ERROR - 短信服务错误
+	ErrorNoCarrier                   // NO CARRIER
+	ErrorNoAnswer                    // NO ANSWER
+	ErrorNoDialtone                  // NO DIALTONE
+	ErrorBusy                        // BUSY
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorGeneric:
+		return "ERROR"
+	case ErrorCME:
+		return "+CME ERROR"
+	case ErrorCMS:
+		return "+CMS ERROR"
+	case ErrorNoCarrier:
+		return "NO CARRIER"
+	case ErrorNoAnswer:
+		return "NO ANSWER"
+	case ErrorNoDialtone:
+		return "NO DIALTONE"
+	case ErrorBusy:
+		return "BUSY"
+	}
+	return "unknown(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Error 设备返回的错误结果码
+//
+// 可通过 errors.As 取出后按 Kind 和 Code 判断，或通过 errors.Is 与 ErrSIMPINRequired 等预定义错误比较。
+type Error struct {
+	Kind    ErrorKind // 错误类型
+	Code    int       // 错误码，仅 CME/CMS 错误有效，未知时为 -1
+	Message string    // 错误描述，数字模式下由错误码表查得
+	Command string    // 出错的命令
+}
+
+func (e *Error) Error() string {
+	msg := e.Kind.String()
+	if e.Code >= 0 {
+		msg += " " + strconv.Itoa(e.Code)
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Command != "" {
+		msg = e.Command + ": " + msg
+	}
+	return msg
+}
+
+// Is 比较错误类型和错误码，用于 errors.Is
+//
+// 含义相同的 CME 和 CMS 错误码视为同一错误，如 +CMS ERROR: 311 与 ErrSIMPINRequired（CME 11）匹配。
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	if e.Kind == t.Kind {
+		return t.Code < 0 || e.Code == t.Code
+	}
+
+	switch {
+	case e.Kind == ErrorCME && t.Kind == ErrorCMS:
+		code, ok := cmsEquivalents[e.Code]
+		return ok && code == t.Code
+	case e.Kind == ErrorCMS && t.Kind == ErrorCME:
+		code, ok := cmsEquivalents[t.Code]
+		return ok && code == e.Code
+	}
+	return false
+}
+
+// 常用 CME 错误码（3GPP TS 27.007 9.2）
+const (
+	CMEOperationNotAllowed   = 3
+	CMEOperationNotSupported = 4
+	CMEPHSIMPINRequired      = 5
+	CMESIMNotInserted        = 10
+	CMESIMPINRequired        = 11
+	CMESIMPUKRequired        = 12
+	CMESIMFailure            = 13
+	CMESIMBusy               = 14
+	CMEIncorrectPassword     = 16
+	CMESIMPIN2Required       = 17
+	CMESIMPUK2Required       = 18
+	CMEMemoryFull            = 20
+	CMEInvalidIndex          = 21
+	CMENotFound              = 22
+	CMEMemoryFailure         = 23
+	CMENoNetworkService      = 30
+	CMENetworkTimeout        = 31
+	CMEUnknown               = 100
+)
+
+// 常用 CMS 错误码（3GPP TS 27.005 3.2.5）
+const (
+	CMSOperationNotAllowed   = 302
+	CMSOperationNotSupported = 303
+	CMSInvalidPDUParameter   = 304
+	CMSInvalidTextParameter  = 305
+	CMSSIMNotInserted        = 310
+	CMSSIMPINRequired        = 311
+	CMSPHSIMPINRequired      = 312
+	CMSSIMFailure            = 313
+	CMSSIMBusy               = 314
+	CMSSIMPUKRequired        = 316
+	CMSSIMPIN2Required       = 317
+	CMSSIMPUK2Required       = 318
+	CMSMemoryFailure         = 320
+	CMSInvalidMemoryIndex    = 321
+	CMSMemoryFull            = 322
+	CMSSMSCAddressUnknown    = 330
+	CMSNoNetworkService      = 331
+	CMSNetworkTimeout        = 332
+	CMSUnknown               = 500
+)
+
+// cmsEquivalents CME 错误码与含义相同的 CMS 错误码
+var cmsEquivalents = map[int]int{
+	CMEOperationNotAllowed:   CMSOperationNotAllowed,
+	CMEOperationNotSupported: CMSOperationNotSupported,
+	CMEPHSIMPINRequired:      CMSPHSIMPINRequired,
+	CMESIMNotInserted:        CMSSIMNotInserted,
+	CMESIMPINRequired:        CMSSIMPINRequired,
+	CMESIMPUKRequired:        CMSSIMPUKRequired,
+	CMESIMFailure:            CMSSIMFailure,
+	CMESIMBusy:               CMSSIMBusy,
+	CMESIMPIN2Required:       CMSSIMPIN2Required,
+	CMESIMPUK2Required:       CMSSIMPUK2Required,
+	CMEMemoryFull:            CMSMemoryFull,
+	CMEInvalidIndex:          CMSInvalidMemoryIndex,
+	CMEMemoryFailure:         CMSMemoryFailure,
+	CMENoNetworkService:      CMSNoNetworkService,
+	CMENetworkTimeout:        CMSNetworkTimeout,
+	CMEUnknown:               CMSUnknown,
+}
+
+// 预定义错误，用于 errors.Is，CME 和 CMS 中含义相同的错误码均可匹配
+var (
+	ErrSIMNotInserted = &Error{Kind: ErrorCME, Code: CMESIMNotInserted}
+	ErrSIMPINRequired = &Error{Kind: ErrorCME, Code: CMESIMPINRequired}
+	ErrSIMPUKRequired = &Error{Kind: ErrorCME, Code: CMESIMPUKRequired}
+	ErrSIMBusy        = &Error{Kind: ErrorCME, Code: CMESIMBusy}
+	ErrBadPassword    = &Error{Kind: ErrorCME, Code: CMEIncorrectPassword}
+	ErrNotFound       = &Error{Kind: ErrorCME, Code: CMENotFound}
+	ErrMemoryFull     = &Error{Kind: ErrorCMS, Code: CMSMemoryFull}
+	ErrNoCarrier      = &Error{Kind: ErrorNoCarrier, Code: -1}
+	ErrBusy           = &Error{Kind: ErrorBusy, Code: -1}
+)
+
+// CMEErrors +CME ERROR 错误码表（3GPP TS 27.007 9.2）
+var CMEErrors = map[int]string{
+	0:   "phone failure",
+	1:   "no connection to phone",
+	2:   "phone-adaptor link reserved",
+	3:   "operation not allowed",
+	4:   "operation not supported",
+	5:   "PH-SIM PIN required",
+	6:   "PH-FSIM PIN required",
+	7:   "PH-FSIM PUK required",
+	10:  "SIM not inserted",
+	11:  "SIM PIN required",
+	12:  "SIM PUK required",
+	13:  "SIM failure",
+	14:  "SIM busy",
+	15:  "SIM wrong",
+	16:  "incorrect password",
+	17:  "SIM PIN2 required",
+	18:  "SIM PUK2 required",
+	20:  "memory full",
+	21:  "invalid index",
+	22:  "not found",
+	23:  "memory failure",
+	24:  "text string too long",
+	25:  "invalid characters in text string",
+	26:  "dial string too long",
+	27:  "invalid characters in dial string",
+	30:  "no network service",
+	31:  "network timeout",
+	32:  "network not allowed - emergency calls only",
+	40:  "network personalization PIN required",
+	41:  "network personalization PUK required",
+	42:  "network subset personalization PIN required",
+	43:  "network subset personalization PUK required",
+	44:  "service provider personalization PIN required",
+	45:  "service provider personalization PUK required",
+	46:  "corporate personalization PIN required",
+	47:  "corporate personalization PUK required",
+	48:  "hidden key required",
+	49:  "EAP method not supported",
+	50:  "incorrect parameters",
+	100: "unknown",
+	103: "illegal MS",
+	106: "illegal ME",
+	107: "GPRS services not allowed",
+	111: "PLMN not allowed",
+	112: "location area not allowed",
+	113: "roaming not allowed in this location area",
+	132: "service option not supported",
+	133: "requested service option not subscribed",
+	134: "service option temporarily out of order",
+	148: "unspecified GPRS error",
+	149: "PDP authentication failure",
+	150: "invalid mobile class",
+}
+
+// CMSErrors +CMS ERROR 错误码表（3GPP TS 27.005 3.2.5）
+var CMSErrors = map[int]string{
+	1:   "unassigned (unallocated) number",
+	8:   "operator determined barring",
+	10:  "call barred",
+	21:  "short message transfer rejected",
+	27:  "destination out of service",
+	28:  "unidentified subscriber",
+	29:  "facility rejected",
+	30:  "unknown subscriber",
+	38:  "network out of order",
+	41:  "temporary failure",
+	42:  "congestion",
+	47:  "resources unavailable, unspecified",
+	50:  "requested facility not subscribed",
+	69:  "requested facility not implemented",
+	81:  "invalid short message transfer reference value",
+	95:  "invalid message, unspecified",
+	96:  "invalid mandatory information",
+	97:  "message type non-existent or not implemented",
+	98:  "message not compatible with short message protocol state",
+	99:  "information element non-existent or not implemented",
+	111: "protocol error, unspecified",
+	127: "interworking, unspecified",
+	128: "telematic interworking not supported",
+	129: "short message type 0 not supported",
+	130: "cannot replace short message",
+	143: "unspecified TP-PID error",
+	144: "data coding scheme (alphabet) not supported",
+	145: "message class not supported",
+	159: "unspecified TP-DCS error",
+	160: "command cannot be actioned",
+	161: "command unsupported",
+	175: "unspecified TP-Command error",
+	176: "TPDU not supported",
+	192: "SC busy",
+	193: "no SC subscription",
+	194: "SC system failure",
+	195: "invalid SME address",
+	196: "destination SME barred",
+	197: "SM rejected-duplicate SM",
+	198: "TP-VPF not supported",
+	199: "TP-VP not supported",
+	208: "D0 SIM SMS storage full",
+	209: "no SMS storage capability in SIM",
+	210: "error in MS",
+	211: "memory capacity exceeded",
+	212: "SIM application toolkit busy",
+	213: "SIM data download error",
+	255: "unspecified error cause",
+	300: "ME failure",
+	301: "SMS service of ME reserved",
+	302: "operation not allowed",
+	303: "operation not supported",
+	304: "invalid PDU mode parameter",
+	305: "invalid text mode parameter",
+	310: "SIM not inserted",
+	311: "SIM PIN required",
+	312: "PH-SIM PIN required",
+	313: "SIM failure",
+	314: "SIM busy",
+	315: "SIM wrong",
+	316: "SIM PUK required",
+	317: "SIM PIN2 required",
+	318: "SIM PUK2 required",
+	320: "memory failure",
+	321: "invalid memory index",
+	322: "memory full",
+	330: "SMSC address unknown",
+	331: "no network service",
+	332: "network timeout",
+	340: "no +CNMA acknowledgement expected",
+	500: "unknown error",
+}
+
+// ErrorReportMode 错误上报模式（AT+CMEE）
+type ErrorReportMode int
+
+const (
+	ErrorReportDisabled ErrorReportMode = 0 // 仅返回 ERROR
+	ErrorReportNumeric  ErrorReportMode = 1 // 返回数字错误码
+	ErrorReportVerbose  ErrorReportMode = 2 // 返回文字错误描述
+)
+
+// ParseError 将错误结果码解析为 *Error，非错误响应返回 nil
+func (rs *ResponseSet) ParseError(line string) *Error {
+	kinds := []struct {
+		prefix string
+		kind   ErrorKind
+		table  map[int]string
+	}{
+		{rs.CMEError, ErrorCME, CMEErrors},
+		{rs.CMSError, ErrorCMS, CMSErrors},
+		{rs.NoCarrier, ErrorNoCarrier, nil},
+		{rs.NoAnswer, ErrorNoAnswer, nil},
+		{rs.NoDialtone, ErrorNoDialtone, nil},
+		{rs.Busy, ErrorBusy, nil},
+		{rs.Error, ErrorGeneric, nil},
+	}
+
+	for _, k := range kinds {
+		if k.prefix == "" || !strings.HasPrefix(line, k.prefix) {
+			continue
+		}
+		e := &Error{Kind: k.kind, Code: -1}
+		if k.table != nil {
+			detail := strings.TrimSpace(strings.TrimPrefix(line, k.prefix))
+			detail = strings.TrimSpace(strings.TrimPrefix(detail, ":"))
+			e.Code, e.Message = lookupError(k.table, detail)
+		}
+		return e
+	}
+
+	return nil
+}
+
+// lookupError 根据数字错误码或文字描述查找错误
+func lookupError(table map[int]string, detail string) (int, string) {
+	if code, err := strconv.Atoi(detail); err == nil {
+		if msg, ok := table[code]; ok {
+			return code, msg
+		}
+		return code, fmt.Sprintf("error %d", code)
+	}
+	for code, msg := range table {
+		if strings.EqualFold(msg, detail) {
+			return code, detail
+		}
+	}
+	return -1, detail
+}
@@ -0,0 +1,45 @@
+package at_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rehiy/modem/at"
+)
+
+func TestErrorIs(t *testing.T) {
+	tests := []struct {
+		err    *at.Error
+		target error
+		want   bool
+	}{
+		{&at.Error{Kind: at.ErrorCME, Code: at.CMESIMPINRequired}, at.ErrSIMPINRequired, true},
+		{&at.Error{Kind: at.ErrorCMS, Code: at.CMSSIMPINRequired}, at.ErrSIMPINRequired, true},
+		{&at.Error{Kind: at.ErrorCMS, Code: at.CMSSIMNotInserted}, at.ErrSIMNotInserted, true},
+		{&at.Error{Kind: at.ErrorCMS, Code: at.CMSMemoryFull}, at.ErrMemoryFull, true},
+		{&at.Error{Kind: at.ErrorCME, Code: at.CMEMemoryFull}, at.ErrMemoryFull, true},
+		{&at.Error{Kind: at.ErrorCMS, Code: at.CMSSIMPUKRequired}, at.ErrSIMPUKRequired, true},
+		{&at.Error{Kind: at.ErrorCMS, Code: at.CMSSIMBusy}, at.ErrSIMBusy, true},
+		{&at.Error{Kind: at.ErrorCME, Code: at.CMEMemoryFull}, at.ErrSIMPINRequired, false},
+		{&at.Error{Kind: at.ErrorCMS, Code: at.CMSInvalidPDUParameter}, at.ErrNotFound, false},
+		{&at.Error{Kind: at.ErrorGeneric, Code: -1}, at.ErrSIMPINRequired, false},
+		{&at.Error{Kind: at.ErrorNoCarrier, Code: -1}, at.ErrNoCarrier, true},
+	}
+	for _, tt := range tests {
+		err := fmt.Errorf("wrapped: %w", tt.err)
+		if got := errors.Is(err, tt.target); got != tt.want {
+			t.Errorf("errors.Is(%v, %v) = %v, want %v", tt.err, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestCMSErrorMapping(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`AT\+CMGS=.*`, "+CMS ERROR: 311")
+
+	err := d.SendSMSPdu("+8613800138000", "hello")
+	if !errors.Is(err, at.ErrSIMPINRequired) {
+		t.Fatalf("err = %v, want ErrSIMPINRequired", err)
+	}
+}
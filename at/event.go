@@ -0,0 +1,450 @@
+package at
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rehiy/modem/sms"
+	"github.com/rehiy/modem/sms/pdumode"
+	"github.com/rehiy/modem/sms/tpdu"
+)
+
+// Event URC 通知事件
+type Event struct {
+	Kind    string         `json:"kind"`    // 通知类型，即通知前缀，如 "+CMTI"、"RING"
+	Line    string         `json:"line"`    // 原始通知行
+	Param   map[int]string `json:"param"`   // 原始参数
+	Payload string         `json:"payload"` // 多行通知的数据行（如 +CMT 的 PDU），单行通知为空
+	Data    any            `json:"data"`    // 解码后的结构，未识别的通知为 nil
+	Time    time.Time      `json:"time"`    // 到达时间
+}
+
+// SMSReadyEvent 新短信存储通知（+CMTI）
+type SMSReadyEvent struct {
+	Storage string `json:"storage"` // 存储位置，如 "SM"、"ME"
+	Index   int    `json:"index"`   // 存储索引
+}
+
+// SMSContentEvent 短信内容推送（+CMT）
+type SMSContentEvent struct {
+	Alpha  string     `json:"alpha"`  // 发送方在电话簿中的名称
+	Length int        `json:"length"` // TPDU 长度（PDU 模式）
+	TPDU   *tpdu.TPDU `json:"tpdu"`   // 解码后的 SMS-DELIVER（PDU 模式）
+	Number string     `json:"number"` // 发送方号码（文本模式）
+	Time   string     `json:"time"`   // 服务中心时间戳（文本模式）
+	Text   string     `json:"text"`   // 短信内容（文本模式）
+}
+
+// SMSStatusReportEvent 短信状态报告（+CDS）
+type SMSStatusReportEvent struct {
+	Length    int        `json:"length"`    // TPDU 长度（PDU 模式）
+	TPDU      *tpdu.TPDU `json:"tpdu"`      // 解码后的 SMS-STATUS-REPORT（PDU 模式）
+	MR        int        `json:"mr"`        // 对应已发送短信的消息参考号
+	ST        int        `json:"st"`        // TP-ST 状态
+	Recipient string     `json:"recipient"` // 接收方号码
+}
+
+// CellBroadcastEvent 小区广播（+CBM）
+type CellBroadcastEvent struct {
+	Length int    `json:"length"` // PDU 长度
+	PDU    string `json:"pdu"`    // 原始十六进制 PDU
+}
+
+// RingEvent 来电响铃（RING/+CRING）
+type RingEvent struct {
+	Type string `json:"type"` // 呼叫类型，如 "VOICE"，RING 时为空
+}
+
+// CallerIDEvent 来电显示（+CLIP）
+type CallerIDEvent struct {
+	Number   string `json:"number"`   // 来电号码
+	Type     int    `json:"type"`     // 号码类型，129 国内 145 国际
+	Alpha    string `json:"alpha"`    // 电话簿中的名称
+	Validity int    `json:"validity"` // 号码有效性 0 有效 1 被隐藏 2 不可用
+}
+
+// CallWaitingEvent 通话中的等待来电（+CCWA），需先通过 SetCallWaiting 或 GetCallWaiting 开启
+type CallWaitingEvent struct {
+	Number string       `json:"number"` // 来电号码
+	Type   int          `json:"type"`   // 号码类型，129 国内 145 国际
+	Class  ServiceClass `json:"class"`  // 业务类别
+	Alpha  string       `json:"alpha"`  // 电话簿中的名称
+}
+
+// USSDEvent USSD 网络响应（+CUSD）
+type USSDEvent struct {
+	Status  int    `json:"status"`  // 0 无需进一步操作 1 需进一步操作 2 会话被网络终止
+	Message string `json:"message"` // 原始消息内容
+	Text    string `json:"text"`    // 按数据编码方案解码后的消息内容
+	DCS     int    `json:"dcs"`     // 数据编码方案
+}
+
+// SIMStatusEvent SIM 卡状态（+CPIN）
+type SIMStatusEvent struct {
+	Status string `json:"status"` // 状态，如 "READY"、"SIM PIN"
+}
+
+// NetworkTimeEvent 网络时区（+CTZV）
+type NetworkTimeEvent struct {
+	Zone   int           `json:"zone"`   // 时区，单位为 15 分钟
+	Offset time.Duration `json:"offset"` // 相对 UTC 的偏移
+	Time   string        `json:"time"`   // 网络时间（厂商扩展，可能为空）
+}
+
+// EventPolicy 订阅通道已满时的处理策略
+type EventPolicy int
+
+const (
+	EventDropNewest EventPolicy = iota // 丢弃新事件（默认）
+	EventDropOldest                    // 丢弃最早的未读事件
+	EventBlock                         // 阻塞读取循环直到订阅者接收
+)
+
+// subscriber 事件订阅者
+type subscriber struct {
+	kinds  map[string]bool // 订阅的通知类型，为空时订阅全部
+	policy EventPolicy     // 通道满时的处理策略
+	ch     chan Event      // 事件通道
+	done   chan struct{}   // 取消订阅信号
+	once   sync.Once       // 保证只关闭一次
+	mu     sync.Mutex      // 保护 ch 的发送和关闭
+	closed bool            // 通道是否已关闭
+}
+
+// Subscribe 订阅指定类型的通知，kinds 为空时订阅全部通知
+//
+// 事件按到达顺序投递，通道容量和满时的处理策略由 Config.EventBuffer 和 Config.EventPolicy 决定。
+// 返回的取消函数会关闭事件通道；设备关闭时所有订阅通道也会被关闭。
+func (m *Device) Subscribe(kinds ...string) (<-chan Event, func()) {
+	return m.subscribe(m.eventPolicy, kinds...)
+}
+
+// subscribe 以指定策略订阅通知
+func (m *Device) subscribe(policy EventPolicy, kinds ...string) (<-chan Event, func()) {
+	sub := &subscriber{
+		kinds:  map[string]bool{},
+		policy: policy,
+		ch:     make(chan Event, m.eventBuffer),
+		done:   make(chan struct{}),
+	}
+	for _, kind := range kinds {
+		sub.kinds[kind] = true
+	}
+
+	m.subMu.Lock()
+	if m.closed.Load() {
+		m.subMu.Unlock()
+		sub.close()
+		return sub.ch, func() {}
+	}
+	m.subs = append(m.subs, sub)
+	m.subMu.Unlock()
+
+	return sub.ch, func() { m.unsubscribe(sub) }
+}
+
+// subscribeQueued 以 EventBlock 订阅通知并转入无界队列
+//
+// 通知不会丢失，接收方处理缓慢或在处理中发送命令也不会阻塞读取循环。
+// 订阅被设备关闭时先投递队列中剩余的事件再关闭通道；调用取消函数则立即关闭通道。
+func (m *Device) subscribeQueued(kinds ...string) (<-chan Event, func()) {
+	events, cancel := m.subscribe(EventBlock, kinds...)
+	out := make(chan Event)
+	stop := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		defer close(out)
+		queue := []Event{}
+		for events != nil || len(queue) > 0 {
+			var ch chan Event
+			var next Event
+			if len(queue) > 0 {
+				ch, next = out, queue[0]
+			}
+
+			select {
+			case event, ok := <-events:
+				if !ok {
+					events = nil
+				} else {
+					queue = append(queue, event)
+				}
+			case ch <- next:
+				queue = queue[1:]
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return out, func() {
+		once.Do(func() {
+			close(stop)
+			cancel()
+		})
+	}
+}
+
+// handleLegacy 按顺序回调兼容通知处理函数
+func (m *Device) handleLegacy(handler UrcHandler) {
+	events, _ := m.subscribeQueued()
+
+	go func() {
+		for event := range events {
+			if event.Kind != EventConnection && event.Kind != EventCall {
+				handler(event.Kind, event.Param)
+			}
+		}
+	}()
+}
+
+// unsubscribe 取消订阅
+func (m *Device) unsubscribe(sub *subscriber) {
+	m.subMu.Lock()
+	for i, s := range m.subs {
+		if s == sub {
+			m.subs = append(m.subs[:i], m.subs[i+1:]...)
+			break
+		}
+	}
+	m.subMu.Unlock()
+	sub.close()
+}
+
+// closeSubscribers 关闭所有订阅
+func (m *Device) closeSubscribers() {
+	m.subMu.Lock()
+	subs := m.subs
+	m.subs = nil
+	m.subMu.Unlock()
+
+	for _, sub := range subs {
+		sub.close()
+	}
+}
+
+// publish 将通知解码为事件并投递给订阅者，payload 为多行通知的数据行
+func (m *Device) publish(line, payload string) {
+	label, param := parseParam(line)
+	event := Event{
+		Kind:    label,
+		Line:    line,
+		Param:   param,
+		Payload: payload,
+		Data:    m.decodeEvent(label, param, payload),
+		Time:    time.Now(),
+	}
+	m.dispatch(event)
+	m.trackCall(event)
+}
+
+// dispatch 将事件投递给订阅了该类型的订阅者
+func (m *Device) dispatch(event Event) {
+	m.subMu.Lock()
+	subs := append([]*subscriber{}, m.subs...)
+	m.subMu.Unlock()
+
+	for _, sub := range subs {
+		if len(sub.kinds) == 0 || sub.kinds[event.Kind] {
+			if !sub.send(event, sub.policy) {
+				m.printf("discarding event: %s", event.Line)
+			}
+		}
+	}
+}
+
+// decodeEvent 将已知通知解码为对应结构
+func (m *Device) decodeEvent(label string, param map[int]string, payload string) any {
+	ns := &m.notifications
+	switch label {
+	case ns.SMSContent:
+		// 文本模式格式: +CMT: <oa>,[<alpha>],<scts>[,<tooa>,<fo>,<pid>,<dcs>,...]，下一行为正文
+		if len(param) > 2 {
+			charset := m.currentCharset()
+			dcs := -1
+			if v, ok := param[6]; ok && v != "" {
+				dcs = parseInt(v)
+			}
+			return SMSContentEvent{
+				Alpha:  param[1],
+				Number: decodeTextField(charset, param[0]),
+				Time:   parseTextTime(param[2]),
+				Text:   decodeTextBody(charset, dcs, payload),
+			}
+		}
+		// PDU 模式格式: +CMT: [<alpha>],<length>，下一行为 PDU
+		if len(param) != 2 {
+			return nil
+		}
+		pdu, err := decodePdu(payload)
+		if err != nil {
+			m.printf("decode %s error: %v", label, err)
+			return nil
+		}
+		return SMSContentEvent{Alpha: param[0], Length: parseInt(param[1]), TPDU: pdu}
+
+	case ns.SMSStatusReport:
+		// 文本模式格式: +CDS: <fo>,<mr>,[<ra>],[<tora>],<scts>,<dt>,<st>
+		if len(param) >= 7 {
+			return SMSStatusReportEvent{
+				MR:        parseInt(param[1]),
+				ST:        parseInt(param[6]),
+				Recipient: decodeTextField(m.currentCharset(), param[2]),
+			}
+		}
+		// PDU 模式格式: +CDS: <length>，下一行为 PDU
+		if len(param) != 1 {
+			return nil
+		}
+		pdu, err := decodePdu(payload)
+		if err != nil {
+			m.printf("decode %s error: %v", label, err)
+			return nil
+		}
+		return SMSStatusReportEvent{
+			Length:    parseInt(param[0]),
+			TPDU:      pdu,
+			MR:        int(pdu.MR),
+			ST:        int(pdu.ST),
+			Recipient: pdu.RA.Number(),
+		}
+
+	case ns.CellBroadcast:
+		// 格式: +CBM: <length>，下一行为 PDU
+		return CellBroadcastEvent{Length: parseInt(param[0]), PDU: payload}
+
+	case ns.SMSReady:
+		// 格式: +CMTI: "SM",3
+		return SMSReadyEvent{Storage: param[0], Index: parseInt(param[1])}
+
+	case ns.NetworkReg, ns.GPRSReg, ns.EPSReg, ns.Reg5G:
+		// 格式: +CREG: 1,"1A2B","0C3D5E",7
+		return parseRegistration(param, false)
+
+	case ns.Ring:
+		return RingEvent{}
+
+	case ns.CallRing:
+		// 格式: +CRING: VOICE
+		return RingEvent{Type: param[0]}
+
+	case ns.CallerID:
+		// 格式: +CLIP: "+8613800138000",145,,,"",0
+		return CallerIDEvent{
+			Number:   param[0],
+			Type:     parseInt(param[1]),
+			Alpha:    param[4],
+			Validity: parseInt(param[5]),
+		}
+
+	case ns.CallWaiting:
+		// 格式: +CCWA: "+8613800138000",145,1,"Alice"
+		return CallWaitingEvent{
+			Number: param[0],
+			Type:   parseInt(param[1]),
+			Class:  ServiceClass(parseInt(param[2])),
+			Alpha:  param[3],
+		}
+
+	case ns.CallList:
+		// 格式: +CLCC: 1,0,0,0,0,"+8613800138000",145
+		if call, ok := parseCall(m.currentCharset(), param); ok {
+			return call
+		}
+		return nil
+
+	case ns.USSD:
+		// 格式: +CUSD: 0,"余额 10 元",15
+		reply := newUSSDReply(m.currentCharset(), param)
+		return USSDEvent{
+			Status:  int(reply.Status),
+			Message: reply.Message,
+			Text:    reply.Text,
+			DCS:     parseInt(param[2]),
+		}
+
+	case ns.SIMStatus:
+		// 格式: +CPIN: READY
+		return SIMStatusEvent{Status: param[0]}
+
+	case ns.NetworkTime:
+		// 格式: +CTZV: +32 或 +CTZV: +32,"24/01/01,12:00:00"
+		zone := parseInt(strings.TrimPrefix(param[0], "+"))
+		return NetworkTimeEvent{
+			Zone:   zone,
+			Offset: time.Duration(zone) * 15 * time.Minute,
+			Time:   strings.Join(mapValues(param, 1), ","),
+		}
+	}
+	return nil
+}
+
+// send 按策略投递事件，事件被丢弃时返回 false
+func (s *subscriber) send(event Event, policy EventPolicy) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return true
+	}
+
+	switch policy {
+	case EventBlock:
+		select {
+		case s.ch <- event:
+		case <-s.done:
+		}
+		return true
+
+	case EventDropOldest:
+		for {
+			select {
+			case s.ch <- event:
+				return true
+			default:
+			}
+			select {
+			case <-s.ch:
+			default:
+			}
+		}
+
+	default:
+		select {
+		case s.ch <- event:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// close 关闭订阅通道
+func (s *subscriber) close() {
+	s.once.Do(func() {
+		close(s.done)
+		s.mu.Lock()
+		s.closed = true
+		close(s.ch)
+		s.mu.Unlock()
+	})
+}
+
+// mapValues 按顺序返回从 start 开始的参数
+func mapValues(param map[int]string, start int) []string {
+	values := []string{}
+	for i := start; i < len(param); i++ {
+		values = append(values, param[i])
+	}
+	return values
+}
+
+// decodePdu 解析带 SMSC 地址的十六进制 PDU
+func decodePdu(pduHex string) (*tpdu.TPDU, error) {
+	pdu, err := pdumode.UnmarshalHexString(pduHex)
+	if err != nil {
+		return nil, err
+	}
+	return sms.Unmarshal(pdu.TPDU)
+}
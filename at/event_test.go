@@ -0,0 +1,59 @@
+package at_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rehiy/modem/port/sim"
+)
+
+func TestLegacyHandlerKeepsAllEvents(t *testing.T) {
+	s := sim.New(&sim.Config{Echo: true, ReadTimeout: 10 * time.Millisecond})
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	indices := []string{}
+	handler := func(label string, param map[int]string) {
+		<-release
+		mu.Lock()
+		indices = append(indices, param[1])
+		mu.Unlock()
+	}
+
+	t.Cleanup(func() { s.Close() })
+	d := newDevice(t, s, handler, nil)
+
+	// 处理函数阻塞期间的通知超过订阅缓冲，且不影响命令收发
+	const count = 40
+	for i := 0; i < count; i++ {
+		s.URC(fmt.Sprintf(`+CMTI: "SM",%d`, i))
+	}
+	if err := d.Test(); err != nil {
+		t.Fatalf("Test: %v", err)
+	}
+	close(release)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(indices)
+		mu.Unlock()
+		if n == count {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(indices) != count {
+		t.Fatalf("handler called %d times, want %d", len(indices), count)
+	}
+	for i, index := range indices {
+		if index != fmt.Sprint(i) {
+			t.Fatalf("event %d has index %s", i, index)
+		}
+	}
+}
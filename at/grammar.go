@@ -0,0 +1,272 @@
+package at
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ValueKind 响应参数的类型
+type ValueKind int
+
+const (
+	ValueEmpty  ValueKind = iota // 缺省参数，如 "+CNUM: ,..." 的第一个参数
+	ValueString                  // 字符串，带引号或无法识别为其他类型的文本
+	ValueInt                     // 十进制整数
+	ValueHex                     // 不带引号且含 A-F 的十六进制数，如 "1A2B"
+	ValueRange                   // 范围，如 "0-4"
+	ValueList                    // 括号内的列表，如 "(0,1)"、"(2,\"CMCC\",\"46000\")"
+)
+
+func (k ValueKind) String() string {
+	switch k {
+	case ValueEmpty:
+		return "empty"
+	case ValueString:
+		return "string"
+	case ValueInt:
+		return "int"
+	case ValueHex:
+		return "hex"
+	case ValueRange:
+		return "range"
+	case ValueList:
+		return "list"
+	}
+	return "unknown(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Value 按 3GPP TS 27.007 信息响应语法解析的参数
+type Value struct {
+	Kind  ValueKind // 参数类型
+	Raw   string    // 原始文本，含引号和括号
+	Text  string    // 字符串内容（已去引号），其他类型同 Raw
+	Num   int       // 整数或十六进制数的值
+	Min   int       // 范围下限
+	Max   int       // 范围上限
+	Items []Value   // 列表元素
+}
+
+// String 返回参数文本，字符串已去除引号
+func (v Value) String() string {
+	return v.Text
+}
+
+// Int 返回整数值，十六进制参数返回其数值，带引号的数字字符串按十进制解析
+func (v Value) Int() (int, bool) {
+	switch v.Kind {
+	case ValueInt, ValueHex:
+		return v.Num, true
+	case ValueString:
+		n, err := strconv.Atoi(v.Text)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// Hex 按十六进制解析参数文本，如 LAC "1A2B" 或 "0C3D"
+func (v Value) Hex() (int, bool) {
+	if v.Kind != ValueString && v.Kind != ValueInt && v.Kind != ValueHex {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(strings.TrimPrefix(v.Text, "0x"), 16, 64)
+	return int(n), err == nil
+}
+
+// Range 返回范围的上下限，单个整数时上下限相同，只含一个范围的列表（如 "(0-4)"）同样有效
+func (v Value) Range() (int, int, bool) {
+	switch v.Kind {
+	case ValueRange:
+		return v.Min, v.Max, true
+	case ValueInt:
+		return v.Num, v.Num, true
+	case ValueList:
+		if len(v.Items) == 1 {
+			return v.Items[0].Range()
+		}
+	}
+	return 0, 0, false
+}
+
+// List 返回列表元素，非列表参数作为单元素列表，缺省参数返回空列表
+func (v Value) List() []Value {
+	switch v.Kind {
+	case ValueList:
+		return v.Items
+	case ValueEmpty:
+		return []Value{}
+	}
+	return []Value{v}
+}
+
+// Ints 返回列表中的全部整数，范围按上下限展开，如 "(0-2,5)" 返回 0、1、2、5
+func (v Value) Ints() []int {
+	ints := []int{}
+	for _, item := range v.List() {
+		if item.Kind == ValueRange {
+			for n := item.Min; n <= item.Max && n-item.Min < 1024; n++ {
+				ints = append(ints, n)
+			}
+			continue
+		}
+		if n, ok := item.Int(); ok {
+			ints = append(ints, n)
+		}
+	}
+	return ints
+}
+
+// ParseLine 解析信息响应行，如 "+COPS: (2,\"CMCC\",\"46000\",7),,(0-4)"，返回标签和参数
+//
+// 没有 ":" 的行返回整行作为标签和空参数列表；语法错误时返回已解析的参数和错误。
+func ParseLine(line string) (string, []Value, error) {
+	label, rest, found := strings.Cut(line, ":")
+	if !found {
+		return strings.TrimSpace(line), []Value{}, nil
+	}
+	values, err := ParseValues(rest)
+	return strings.TrimSpace(label), values, err
+}
+
+// ParseValues 解析以逗号分隔的参数列表，字符串内和括号内的逗号不作为分隔符
+func ParseValues(s string) ([]Value, error) {
+	p := &parser{s: s}
+	values := p.list(0)
+	if p.err == nil && p.pos < len(p.s) {
+		p.fail("unexpected %q", p.s[p.pos])
+	}
+	return values, p.err
+}
+
+// parser 参数解析器，出错时记录第一个错误并尽量继续解析
+type parser struct {
+	s   string
+	pos int
+	err error
+}
+
+// fail 记录解析错误
+func (p *parser) fail(format string, args ...any) {
+	if p.err == nil {
+		p.err = fmt.Errorf("parse %q at offset %d: %s", p.s, p.pos, fmt.Sprintf(format, args...))
+	}
+}
+
+// list 解析以逗号分隔的参数，直到字符串结束或遇到不匹配的 ")"
+func (p *parser) list(depth int) []Value {
+	values := []Value{}
+	for {
+		values = append(values, p.value(depth))
+		if p.pos >= len(p.s) || p.s[p.pos] != ',' {
+			return values
+		}
+		p.pos++
+	}
+}
+
+// value 解析单个参数
+func (p *parser) value(depth int) Value {
+	p.skipSpace()
+	start := p.pos
+	if p.pos >= len(p.s) {
+		return Value{Kind: ValueEmpty}
+	}
+
+	switch p.s[p.pos] {
+	case '"':
+		return p.quoted(start)
+	case '(':
+		p.pos++
+		items := p.list(depth + 1)
+		if p.pos >= len(p.s) || p.s[p.pos] != ')' {
+			p.fail("missing ')'")
+		} else {
+			p.pos++
+		}
+		raw := p.s[start:p.pos]
+		p.skipSpace()
+		return Value{Kind: ValueList, Raw: raw, Text: raw, Items: items}
+	}
+
+	// 不带引号的参数，到逗号或本层的右括号为止
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if c == ',' || (c == ')' && depth > 0) {
+			break
+		}
+		if c == '"' || c == '(' || c == ')' {
+			p.fail("unexpected %q", c)
+			p.pos = len(p.s)
+			break
+		}
+		p.pos++
+	}
+	return classify(strings.TrimSpace(p.s[start:p.pos]))
+}
+
+// quoted 解析带引号的字符串，引号之后到分隔符之前的文本并入字符串
+func (p *parser) quoted(start int) Value {
+	end := strings.IndexByte(p.s[p.pos+1:], '"')
+	if end < 0 {
+		p.fail("unterminated string")
+		p.pos = len(p.s)
+		return Value{Kind: ValueString, Raw: p.s[start:], Text: p.s[start+1:]}
+	}
+	text := p.s[p.pos+1 : p.pos+1+end]
+	p.pos += end + 2
+
+	// 部分模块在字符串之后紧跟未加引号的内容，如 "abc"def
+	rest := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] != ',' && p.s[p.pos] != ')' {
+		p.pos++
+	}
+	text += strings.TrimRight(p.s[rest:p.pos], " ")
+	return Value{Kind: ValueString, Raw: strings.TrimSpace(p.s[start:p.pos]), Text: text}
+}
+
+// skipSpace 跳过空白
+func (p *parser) skipSpace() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// classify 识别不带引号的参数类型
+func classify(raw string) Value {
+	v := Value{Kind: ValueString, Raw: raw, Text: raw}
+	if raw == "" {
+		v.Kind = ValueEmpty
+		return v
+	}
+
+	if n, err := strconv.Atoi(raw); err == nil {
+		v.Kind, v.Num = ValueInt, n
+		return v
+	}
+	if lo, hi, found := strings.Cut(raw, "-"); found {
+		min, err1 := strconv.Atoi(lo)
+		max, err2 := strconv.Atoi(hi)
+		if err1 == nil && err2 == nil {
+			v.Kind, v.Min, v.Max = ValueRange, min, max
+			return v
+		}
+	}
+	if isHex(raw) {
+		n, err := strconv.ParseInt(raw, 16, 64)
+		if err == nil {
+			v.Kind, v.Num = ValueHex, int(n)
+		}
+	}
+	return v
+}
+
+// isHex 检查是否为十六进制数字串
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,143 @@
+package at_test
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/rehiy/modem/at"
+)
+
+var grammarSeeds = []string{
+	`+COPS: (2,"CHINA MOBILE","CMCC","46000",7),(1,"CHINA UNICOM","UNICOM","46001",7),,(0-4),(0-2)`,
+	`+CMGL: 1,"REC UNREAD","+8613800138000","Zhang, San","24/01/01,12:00:00+32"`,
+	`+CNMI: (0-2),(0-3),(0,2),(0-2),(0,1)`,
+	`+CPBR: (1-250),40,14`,
+	`+CREG: 2,1,"1A2B","0C3D5E",7`,
+	`+CNUM: ,"+8613800138000",129`,
+	`+CSQ: 20,99`,
+	`+CUSD: 0,"abc"def,15`,
+	`+X: ((1,2),(3-4)),"(not a list)"`,
+	`+X: "unterminated`,
+	`+X: (1,2`,
+	`+X: 1)`,
+	`OK`,
+}
+
+// stripSpace 去除全部空白，解析器会去掉参数两侧的空白
+func stripSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// joinRaw 按逗号连接参数的原始文本
+func joinRaw(values []at.Value) string {
+	raws := make([]string, len(values))
+	for i, v := range values {
+		raws[i] = v.Raw
+	}
+	return strings.Join(raws, ",")
+}
+
+func FuzzParseLine(f *testing.F) {
+	for _, seed := range grammarSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		label, values, err := at.ParseLine(line)
+		if values == nil {
+			t.Fatalf("ParseLine(%q) returned nil values", line)
+		}
+		if err != nil || !strings.Contains(line, ":") {
+			return
+		}
+		// 解析成功时标签和参数覆盖整行
+		if got := stripSpace(label + ":" + joinRaw(values)); got != stripSpace(line) {
+			t.Fatalf("ParseLine(%q) consumed %q", line, got)
+		}
+	})
+}
+
+func FuzzParseValues(f *testing.F) {
+	for _, seed := range grammarSeeds {
+		if _, rest, found := strings.Cut(seed, ":"); found {
+			f.Add(rest)
+		}
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		values, err := at.ParseValues(s)
+		if len(values) == 0 {
+			t.Fatalf("ParseValues(%q) returned no values", s)
+		}
+		if err != nil {
+			return
+		}
+		if got := stripSpace(joinRaw(values)); got != stripSpace(s) {
+			t.Fatalf("ParseValues(%q) consumed %q", s, got)
+		}
+		for _, v := range values {
+			v.Ints()
+		}
+	})
+}
+
+func TestParseLine(t *testing.T) {
+	label, values, err := at.ParseLine(grammarSeeds[0])
+	if err != nil {
+		t.Fatalf("ParseLine: %v", err)
+	}
+	if label != "+COPS" || len(values) != 5 {
+		t.Fatalf("ParseLine = %q, %d values", label, len(values))
+	}
+	if op := values[1].Items; len(op) != 5 || op[1].String() != "CHINA UNICOM" || op[4].Kind != at.ValueInt {
+		t.Fatalf("operator = %+v", op)
+	}
+	if values[2].Kind != at.ValueEmpty {
+		t.Fatalf("values[2].Kind = %s", values[2].Kind)
+	}
+	if lo, hi, ok := values[3].Range(); !ok || lo != 0 || hi != 4 {
+		t.Fatalf("values[3].Range() = %d, %d, %v", lo, hi, ok)
+	}
+
+	_, values, _ = at.ParseLine(grammarSeeds[1])
+	if len(values) != 5 || values[3].String() != "Zhang, San" || values[4].String() != "24/01/01,12:00:00+32" {
+		t.Fatalf("CMGL header = %+v", values)
+	}
+
+	_, values, _ = at.ParseLine(grammarSeeds[2])
+	if got := values[2].Ints(); len(got) != 2 || got[0] != 0 || got[1] != 2 {
+		t.Fatalf("values[2].Ints() = %v", got)
+	}
+
+	if _, _, err := at.ParseLine(`+X: "unterminated`); err == nil {
+		t.Fatal("ParseLine accepted unterminated string")
+	}
+}
+
+func TestParamsKeepKind(t *testing.T) {
+	resp := &at.Response{Lines: []string{`+X: "123",123,1A2B,(0-4),`}}
+	param, ok := resp.Params("+X")
+	if !ok {
+		t.Fatal("Params not found")
+	}
+
+	kinds := []at.ValueKind{at.ValueString, at.ValueInt, at.ValueHex, at.ValueList, at.ValueEmpty}
+	for i, kind := range kinds {
+		if got := param.Value(i).Kind; got != kind {
+			t.Errorf("Value(%d).Kind = %s, want %s", i, got, kind)
+		}
+	}
+	if n, ok := param.Int(0); !ok || n != 123 {
+		t.Errorf("Int(0) = %d, %v", n, ok)
+	}
+	if param.Has(4) || param.Has(5) {
+		t.Error("Has reports missing parameter")
+	}
+	if lo, hi, ok := param.Range(3); !ok || lo != 0 || hi != 4 {
+		t.Errorf("Range(3) = %d, %d, %v", lo, hi, ok)
+	}
+}
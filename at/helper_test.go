@@ -0,0 +1,48 @@
+package at_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rehiy/modem/at"
+	"github.com/rehiy/modem/port/sim"
+)
+
+// newSimDevice 创建连接到模拟器的设备，测试结束时关闭
+func newSimDevice(t *testing.T, config *at.Config) (*at.Device, *sim.Modem) {
+	t.Helper()
+
+	s := sim.New(&sim.Config{Echo: true, ReadTimeout: 10 * time.Millisecond})
+	t.Cleanup(func() { s.Close() })
+	return newDevice(t, s, nil, config), s
+}
+
+// newDevice 创建连接到指定串口的设备，日志输出到测试日志，测试结束时关闭
+func newDevice(t *testing.T, port at.Port, handler at.UrcHandler, config *at.Config) *at.Device {
+	t.Helper()
+
+	if config == nil {
+		config = &at.Config{}
+	}
+	// 后台协程可能在测试结束后仍在记录日志
+	var mu sync.Mutex
+	done := false
+	if config.Printf == nil {
+		config.Printf = func(format string, v ...any) {
+			mu.Lock()
+			defer mu.Unlock()
+			if !done {
+				t.Logf(format, v...)
+			}
+		}
+	}
+	d := at.New(port, handler, config)
+	t.Cleanup(func() {
+		mu.Lock()
+		done = true
+		mu.Unlock()
+		d.Close()
+	})
+	return d
+}
@@ -0,0 +1,268 @@
+package at
+
+import (
+	"strconv"
+)
+
+// ===== 运营商 =====
+
+// Operator 运营商信息（+COPS）
+type Operator struct {
+	Mode       OperatorMode   `json:"mode"`       // 网络选择模式
+	Format     OperatorFormat `json:"format"`     // 运营商名称格式
+	Name       string         `json:"name"`       // 运营商名称，格式由 Format 决定
+	AccessTech AccessTech     `json:"accessTech"` // 接入技术
+}
+
+// OperatorInfo 网络扫描发现的运营商（AT+COPS=?）
+type OperatorInfo struct {
+	Stat       OperatorStat `json:"stat"`       // 可用状态
+	Long       string       `json:"long"`       // 长字母格式名称
+	Short      string       `json:"short"`      // 短字母格式名称
+	Numeric    string       `json:"numeric"`    // 数字格式（MCC+MNC）
+	AccessTech AccessTech   `json:"accessTech"` // 接入技术，未上报时为 AccessTechUnknown
+}
+
+// OperatorStat 运营商可用状态
+type OperatorStat int
+
+const (
+	OperatorStatUnknown   OperatorStat = 0 // 未知
+	OperatorStatAvailable OperatorStat = 1 // 可用
+	OperatorStatCurrent   OperatorStat = 2 // 当前注册
+	OperatorStatForbidden OperatorStat = 3 // 禁止
+)
+
+func (s OperatorStat) String() string {
+	switch s {
+	case OperatorStatUnknown:
+		return "unknown"
+	case OperatorStatAvailable:
+		return "available"
+	case OperatorStatCurrent:
+		return "current"
+	case OperatorStatForbidden:
+		return "forbidden"
+	}
+	return "unknown(" + strconv.Itoa(int(s)) + ")"
+}
+
+// OperatorMode 网络选择模式
+type OperatorMode int
+
+const (
+	OperatorModeAuto       OperatorMode = 0 // 自动选择
+	OperatorModeManual     OperatorMode = 1 // 手动选择
+	OperatorModeDeregister OperatorMode = 2 // 从网络注销
+	OperatorModeFormatOnly OperatorMode = 3 // 仅设置格式
+	OperatorModeManualAuto OperatorMode = 4 // 手动选择失败后自动选择
+)
+
+func (m OperatorMode) String() string {
+	switch m {
+	case OperatorModeAuto:
+		return "automatic"
+	case OperatorModeManual:
+		return "manual"
+	case OperatorModeDeregister:
+		return "deregister"
+	case OperatorModeFormatOnly:
+		return "set format only"
+	case OperatorModeManualAuto:
+		return "manual/automatic"
+	}
+	return "unknown(" + strconv.Itoa(int(m)) + ")"
+}
+
+// OperatorFormat 运营商名称格式
+type OperatorFormat int
+
+const (
+	OperatorFormatLong    OperatorFormat = 0 // 长字母格式
+	OperatorFormatShort   OperatorFormat = 1 // 短字母格式
+	OperatorFormatNumeric OperatorFormat = 2 // 数字格式（MCC+MNC）
+)
+
+func (f OperatorFormat) String() string {
+	switch f {
+	case OperatorFormatLong:
+		return "long alphanumeric"
+	case OperatorFormatShort:
+		return "short alphanumeric"
+	case OperatorFormatNumeric:
+		return "numeric"
+	}
+	return "unknown(" + strconv.Itoa(int(f)) + ")"
+}
+
+// AccessTech 无线接入技术（AcT）
+type AccessTech int
+
+const (
+	AccessTechUnknown     AccessTech = -1 // 未上报
+	AccessTechGSM         AccessTech = 0  // GSM
+	AccessTechGSMCompact  AccessTech = 1  // GSM Compact
+	AccessTechUTRAN       AccessTech = 2  // UTRAN
+	AccessTechEGPRS       AccessTech = 3  // GSM w/EGPRS
+	AccessTechHSDPA       AccessTech = 4  // UTRAN w/HSDPA
+	AccessTechHSUPA       AccessTech = 5  // UTRAN w/HSUPA
+	AccessTechHSPA        AccessTech = 6  // UTRAN w/HSDPA and HSUPA
+	AccessTechEUTRAN      AccessTech = 7  // E-UTRAN
+	AccessTechECGSMIoT    AccessTech = 8  // EC-GSM-IoT
+	AccessTechNBIoT       AccessTech = 9  // E-UTRAN (NB-S1 mode)
+	AccessTechEUTRA5GCN   AccessTech = 10 // E-UTRA connected to a 5GCN
+	AccessTechNR5GCN      AccessTech = 11 // NR connected to a 5GCN
+	AccessTechNGRAN       AccessTech = 12 // NG-RAN
+	AccessTechEUTRANRDual AccessTech = 13 // E-UTRA-NR dual connectivity
+)
+
+var accessTechNames = map[AccessTech]string{
+	AccessTechUnknown:     "unknown",
+	AccessTechGSM:         "GSM",
+	AccessTechGSMCompact:  "GSM Compact",
+	AccessTechUTRAN:       "UTRAN",
+	AccessTechEGPRS:       "GSM w/EGPRS",
+	AccessTechHSDPA:       "UTRAN w/HSDPA",
+	AccessTechHSUPA:       "UTRAN w/HSUPA",
+	AccessTechHSPA:        "UTRAN w/HSDPA and HSUPA",
+	AccessTechEUTRAN:      "E-UTRAN",
+	AccessTechECGSMIoT:    "EC-GSM-IoT",
+	AccessTechNBIoT:       "E-UTRAN (NB-S1)",
+	AccessTechEUTRA5GCN:   "E-UTRA 5GCN",
+	AccessTechNR5GCN:      "NR 5GCN",
+	AccessTechNGRAN:       "NG-RAN",
+	AccessTechEUTRANRDual: "E-UTRA-NR dual connectivity",
+}
+
+func (a AccessTech) String() string {
+	if name, ok := accessTechNames[a]; ok {
+		return name
+	}
+	return "unknown(" + strconv.Itoa(int(a)) + ")"
+}
+
+// ===== 信号质量 =====
+
+// SignalQuality 信号质量（+CSQ）
+type SignalQuality struct {
+	RSSI    int `json:"rssi"`    // 信号强度（dBm），未知时为 0
+	BER     int `json:"ber"`     // 误码率等级 RxQual 0-7，未知时为 -1
+	RawRSSI int `json:"rawRssi"` // 原始信号强度 0-31，99 表示未知
+	RawBER  int `json:"rawBer"`  // 原始误码率 0-7，99 表示未知
+}
+
+// newSignalQuality 根据 +CSQ 原始值构造信号质量
+func newSignalQuality(rssi, ber int) SignalQuality {
+	sq := SignalQuality{RawRSSI: rssi, RawBER: ber, BER: -1}
+	if rssi >= 0 && rssi <= 31 {
+		sq.RSSI = -113 + 2*rssi
+	}
+	if ber >= 0 && ber <= 7 {
+		sq.BER = ber
+	}
+	return sq
+}
+
+// Valid 信号强度是否已知
+func (s SignalQuality) Valid() bool {
+	return s.RawRSSI >= 0 && s.RawRSSI <= 31
+}
+
+// ===== 网络注册 =====
+
+// Registration 网络注册状态（+CREG/+CGREG/+CEREG/+C5GREG）
+type Registration struct {
+	N    int        `json:"n"`    // 上报模式，仅查询结果包含
+	Stat RegStat    `json:"stat"` // 注册状态
+	LAC  int        `json:"lac"`  // 位置区码/跟踪区码，未上报时为 -1
+	CI   int        `json:"ci"`   // 小区 ID，未上报时为 -1
+	AcT  AccessTech `json:"act"`  // 接入技术
+}
+
+// RegStat 网络注册状态
+type RegStat int
+
+const (
+	RegStatNotRegistered  RegStat = 0  // 未注册，未搜索
+	RegStatHome           RegStat = 1  // 已注册本地网络
+	RegStatSearching      RegStat = 2  // 未注册，正在搜索
+	RegStatDenied         RegStat = 3  // 注册被拒绝
+	RegStatUnknown        RegStat = 4  // 未知
+	RegStatRoaming        RegStat = 5  // 已注册漫游网络
+	RegStatSMSOnlyHome    RegStat = 6  // 仅短信，本地网络
+	RegStatSMSOnlyRoaming RegStat = 7  // 仅短信，漫游网络
+	RegStatEmergencyOnly  RegStat = 8  // 仅紧急呼叫
+	RegStatCSFBNotHome    RegStat = 9  // 本地网络，不建议 CSFB
+	RegStatCSFBNotRoaming RegStat = 10 // 漫游网络，不建议 CSFB
+	RegStatNotReported    RegStat = -1 // 未上报
+)
+
+var regStatNames = map[RegStat]string{
+	RegStatNotRegistered:  "not registered",
+	RegStatHome:           "registered, home network",
+	RegStatSearching:      "searching",
+	RegStatDenied:         "registration denied",
+	RegStatUnknown:        "unknown",
+	RegStatRoaming:        "registered, roaming",
+	RegStatSMSOnlyHome:    "registered for SMS only, home network",
+	RegStatSMSOnlyRoaming: "registered for SMS only, roaming",
+	RegStatEmergencyOnly:  "emergency bearer services only",
+	RegStatCSFBNotHome:    "registered, CSFB not preferred, home network",
+	RegStatCSFBNotRoaming: "registered, CSFB not preferred, roaming",
+	RegStatNotReported:    "not reported",
+}
+
+func (s RegStat) String() string {
+	if name, ok := regStatNames[s]; ok {
+		return name
+	}
+	return "unknown(" + strconv.Itoa(int(s)) + ")"
+}
+
+// Registered 是否已注册到网络
+func (s RegStat) Registered() bool {
+	switch s {
+	case RegStatHome, RegStatRoaming, RegStatSMSOnlyHome, RegStatSMSOnlyRoaming, RegStatCSFBNotHome, RegStatCSFBNotRoaming:
+		return true
+	}
+	return false
+}
+
+// Roaming 是否处于漫游状态
+func (s RegStat) Roaming() bool {
+	return s == RegStatRoaming || s == RegStatSMSOnlyRoaming || s == RegStatCSFBNotRoaming
+}
+
+// parseRegistration 解析注册状态参数
+// 查询结果格式: <n>,<stat>[,<lac>,<ci>[,<AcT>]]，通知格式: <stat>[,<lac>,<ci>[,<AcT>]]
+func parseRegistration(param map[int]string, withN bool) Registration {
+	reg := Registration{Stat: RegStatNotReported, LAC: -1, CI: -1, AcT: AccessTechUnknown}
+
+	i := 0
+	if withN {
+		reg.N = parseInt(param[0])
+		i++
+	}
+	if v, ok := param[i]; ok && v != "" {
+		reg.Stat = RegStat(parseInt(v))
+	}
+	if v, ok := param[i+1]; ok && v != "" {
+		reg.LAC = parseHex(v)
+	}
+	if v, ok := param[i+2]; ok && v != "" {
+		reg.CI = parseHex(v)
+	}
+	if v, ok := param[i+3]; ok && v != "" {
+		reg.AcT = AccessTech(parseInt(v))
+	}
+	return reg
+}
+
+// parseHex 解析十六进制整数，失败时返回 -1
+func parseHex(s string) int {
+	v, err := strconv.ParseInt(s, 16, 64)
+	if err != nil {
+		return -1
+	}
+	return int(v)
+}
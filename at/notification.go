@@ -74,6 +74,9 @@ type NotificationSet struct {
 
 	// 其他服务
 	USSD string // +CUSD - 非结构化补充业务数据
+
+	// 多行通知
+	PayloadURCs []string // 头部行之后紧跟一行数据的通知（PDU 或短信文本）
 }
 
 // DefaultNotificationSet 返回默认的URC类型集合
@@ -146,6 +149,9 @@ func DefaultNotificationSet() *NotificationSet {
 
 		// 其他服务
 		USSD: "+CUSD",
+
+		// 多行通知
+		PayloadURCs: []string{"+CMT", "+CDS", "+CBM"},
 	}
 }
 
@@ -175,9 +181,48 @@ func (ns *NotificationSet) IsNotification(line, cmd string) bool {
 			break
 		}
 	}
-	// 避免将命令响应误认为 URC
-	if cmd != "" && urc != "" && urc[0] == '+' {
-		return !strings.HasPrefix(cmd, "AT"+urc)
+	if urc == "" {
+		return false
+	}
+
+	// 当前命令的信息响应与同名通知格式相同（如 AT+CREG? 与 +CREG），按标签区分
+	if name := commandName(cmd); name != "" {
+		label, _, _ := strings.Cut(line, ":")
+		if strings.EqualFold(strings.TrimSpace(label), name) {
+			return false
+		}
+	}
+	return true
+}
+
+// commandName 返回扩展命令名，如 "AT+CREG?" 返回 "+CREG"，基本命令（如 "ATD"）返回空字符串
+func commandName(cmd string) string {
+	cmd = strings.TrimSpace(cmd)
+	if len(cmd) < 3 || !strings.EqualFold(cmd[:2], "AT") {
+		return ""
+	}
+
+	name := cmd[2:]
+	if i := strings.IndexAny(name, "=?"); i >= 0 {
+		name = name[:i]
+	}
+	// 扩展命令以 "+" 或厂商前缀（如 "^"、"$"、"%"）开头
+	if name == "" || !strings.ContainsRune("+^$%*#", rune(name[0])) {
+		return ""
+	}
+	return strings.ToUpper(name)
+}
+
+// HasPayload 检查通知头部之后是否紧跟一行数据
+func (ns *NotificationSet) HasPayload(label string, param map[int]string) bool {
+	for _, item := range ns.PayloadURCs {
+		if item != "" && label == item {
+			// 文本模式的状态报告为单行：+CDS: <fo>,<mr>,...；PDU 模式为 +CDS: <length>
+			if label == ns.SMSStatusReport && len(param) > 1 {
+				return false
+			}
+			return true
+		}
 	}
-	return urc != ""
+	return false
 }
@@ -0,0 +1,240 @@
+package at
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 电话簿存储（AT+CPBS）
+const (
+	PhonebookSIM       = "SM" // SIM 卡电话簿
+	PhonebookME        = "ME" // 模块电话簿
+	PhonebookFixed     = "FD" // 固定拨号号码
+	PhonebookOwn       = "ON" // 本机号码
+	PhonebookEmergency = "EN" // 紧急号码
+	PhonebookDialled   = "DC" // 已拨号码
+	PhonebookReceived  = "RC" // 已接来电
+	PhonebookMissed    = "MC" // 未接来电
+)
+
+// TypeOfNumber 号码类型（TON/NPI），如 129 未知类型、145 国际号码
+type TypeOfNumber int
+
+const (
+	TypeOfNumberUnknown       TypeOfNumber = 129 // 未知类型，ISDN 编号方案
+	TypeOfNumberInternational TypeOfNumber = 145 // 国际号码，号码以 "+" 开头
+	TypeOfNumberNational      TypeOfNumber = 161 // 国内号码
+)
+
+// International 返回是否为国际号码
+func (t TypeOfNumber) International() bool {
+	return t&0x70 == 0x10
+}
+
+func (t TypeOfNumber) String() string {
+	switch t {
+	case TypeOfNumberUnknown:
+		return "unknown"
+	case TypeOfNumberInternational:
+		return "international"
+	case TypeOfNumberNational:
+		return "national"
+	}
+	return "unknown(" + strconv.Itoa(int(t)) + ")"
+}
+
+// numberType 根据号码格式选择号码类型
+func numberType(number string) TypeOfNumber {
+	if strings.HasPrefix(number, "+") {
+		return TypeOfNumberInternational
+	}
+	return TypeOfNumberUnknown
+}
+
+// PhonebookEntry 电话簿条目
+type PhonebookEntry struct {
+	Index  int          `json:"index"`  // 存储索引
+	Number string       `json:"number"` // 号码
+	Type   TypeOfNumber `json:"type"`   // 号码类型
+	Name   string       `json:"name"`   // 名称，已按 TE 字符集解码
+}
+
+// PhonebookStatus 电话簿存储状态
+type PhonebookStatus struct {
+	Storage      string `json:"storage"`      // 当前存储
+	Used         int    `json:"used"`         // 已用条目数，模块不支持时为 -1
+	Total        int    `json:"total"`        // 总条目数，模块不支持时为 -1
+	MinIndex     int    `json:"minIndex"`     // 最小索引
+	MaxIndex     int    `json:"maxIndex"`     // 最大索引
+	NumberLength int    `json:"numberLength"` // 号码最大长度
+	NameLength   int    `json:"nameLength"`   // 名称最大长度
+}
+
+// SelectPhonebook 选择电话簿存储，之后的读写均作用于该存储
+func (m *Device) SelectPhonebook(storage string) error {
+	return m.SelectPhonebookContext(context.Background(), storage)
+}
+
+// SelectPhonebookContext 选择电话簿存储
+func (m *Device) SelectPhonebookContext(ctx context.Context, storage string) error {
+	cmd := fmt.Sprintf(`%s="%s"`, m.commands.PhonebookStorage, storage)
+	return m.SendCommandExpectContext(ctx, cmd, "OK")
+}
+
+// GetPhonebookStatus 查询当前电话簿存储的容量和索引范围
+func (m *Device) GetPhonebookStatus() (PhonebookStatus, error) {
+	return m.GetPhonebookStatusContext(context.Background())
+}
+
+// GetPhonebookStatusContext 查询当前电话簿存储的容量和索引范围
+func (m *Device) GetPhonebookStatusContext(ctx context.Context) (PhonebookStatus, error) {
+	resp, err := m.SendCommandResponseContext(ctx, m.commands.PhonebookStorage+"?")
+	if err != nil {
+		return PhonebookStatus{}, err
+	}
+
+	// 格式: +CPBS: "SM",10,250（部分模块不返回容量）
+	param, ok := resp.Params("+CPBS")
+	if !ok {
+		return PhonebookStatus{}, fmt.Errorf("failed to parse phonebook storage")
+	}
+	status := PhonebookStatus{Storage: param.String(0), Used: -1, Total: -1}
+	if used, ok := param.Int(1); ok {
+		status.Used = used
+	}
+	if total, ok := param.Int(2); ok {
+		status.Total = total
+	}
+
+	resp, err = m.SendCommandResponseContext(ctx, m.commands.ReadPhonebook+"=?")
+	if err != nil {
+		return status, err
+	}
+
+	// 格式: +CPBR: (1-250),40,14
+	values, ok := resp.Values("+CPBR")
+	if !ok || len(values) < 3 {
+		return status, fmt.Errorf("failed to parse phonebook range")
+	}
+	status.MinIndex, status.MaxIndex, _ = values[0].Range()
+	status.NumberLength, _ = values[1].Int()
+	status.NameLength, _ = values[2].Int()
+	return status, nil
+}
+
+// ReadPhonebook 读取索引 start 到 end（含）之间的条目，空位置不返回
+func (m *Device) ReadPhonebook(start, end int) ([]PhonebookEntry, error) {
+	return m.ReadPhonebookContext(context.Background(), start, end)
+}
+
+// ReadPhonebookContext 读取索引 start 到 end（含）之间的条目
+func (m *Device) ReadPhonebookContext(ctx context.Context, start, end int) ([]PhonebookEntry, error) {
+	cmd := fmt.Sprintf("%s=%d,%d", m.commands.ReadPhonebook, start, end)
+	resp, err := m.SendCommandResponseContext(ctx, cmd)
+	if err != nil {
+		// 范围内没有条目时部分模块返回 +CME ERROR: 22（not found）
+		if errors.Is(err, ErrNotFound) {
+			return []PhonebookEntry{}, nil
+		}
+		return nil, err
+	}
+	return m.parsePhonebook(resp.All("+CPBR")), nil
+}
+
+// FindPhonebook 查找名称以 name 开头的条目
+func (m *Device) FindPhonebook(name string) ([]PhonebookEntry, error) {
+	return m.FindPhonebookContext(context.Background(), name)
+}
+
+// FindPhonebookContext 查找名称以 name 开头的条目
+func (m *Device) FindPhonebookContext(ctx context.Context, name string) ([]PhonebookEntry, error) {
+	text, err := encodeQuoted(m.currentCharset(), name)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := fmt.Sprintf(`%s=%s`, m.commands.FindPhonebook, text)
+	resp, err := m.SendCommandResponseContext(ctx, cmd)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return []PhonebookEntry{}, nil
+		}
+		return nil, err
+	}
+	return m.parsePhonebook(resp.All("+CPBF")), nil
+}
+
+// WritePhonebook 写入条目，Index 为 0 时写入第一个空位置；Type 为 0 时按号码格式自动选择
+func (m *Device) WritePhonebook(entry PhonebookEntry) error {
+	return m.WritePhonebookContext(context.Background(), entry)
+}
+
+// WritePhonebookContext 写入条目
+func (m *Device) WritePhonebookContext(ctx context.Context, entry PhonebookEntry) error {
+	// 号码与名称一样按 TE 字符集编码，与文本模式短信的号码一致
+	charset := m.currentCharset()
+	number, err := encodeQuoted(charset, entry.Number)
+	if err != nil {
+		return err
+	}
+	name, err := encodeQuoted(charset, entry.Name)
+	if err != nil {
+		return err
+	}
+
+	typ := entry.Type
+	if typ == 0 {
+		typ = numberType(entry.Number)
+	}
+
+	index := ""
+	if entry.Index > 0 {
+		index = strconv.Itoa(entry.Index)
+	}
+	cmd := fmt.Sprintf(`%s=%s,%s,%d,%s`, m.commands.WritePhonebook, index, number, typ, name)
+	return m.SendCommandExpectContext(ctx, cmd, "OK")
+}
+
+// DeletePhonebook 删除指定索引的条目
+func (m *Device) DeletePhonebook(index int) error {
+	return m.DeletePhonebookContext(context.Background(), index)
+}
+
+// DeletePhonebookContext 删除指定索引的条目
+func (m *Device) DeletePhonebookContext(ctx context.Context, index int) error {
+	cmd := fmt.Sprintf("%s=%d", m.commands.WritePhonebook, index)
+	return m.SendCommandExpectContext(ctx, cmd, "OK")
+}
+
+// parsePhonebook 解析 +CPBR/+CPBF 条目，号码和名称按 TE 字符集解码
+func (m *Device) parsePhonebook(list []Params) []PhonebookEntry {
+	charset := m.currentCharset()
+	entries := []PhonebookEntry{}
+	for _, param := range list {
+		// 格式: +CPBR: 1,"+8613800138000",145,"Alice"
+		index, ok := param.Int(0)
+		if !ok || len(param) < 4 {
+			continue
+		}
+		typ, _ := param.Int(2)
+		entries = append(entries, PhonebookEntry{
+			Index:  index,
+			Number: decodeNumber(charset, param.String(1)),
+			Type:   TypeOfNumber(typ),
+			Name:   decodeTextField(charset, param.String(3)),
+		})
+	}
+	return entries
+}
+
+// decodeNumber 按 TE 字符集解码号码，解码结果不是拨号字符时保留原文，兼容按原样返回号码的模块
+func decodeNumber(charset, s string) string {
+	text, err := decodeString(charset, s)
+	if err != nil || text == "" || strings.Trim(text, "0123456789+*#pPwW,") != "" {
+		return s
+	}
+	return text
+}
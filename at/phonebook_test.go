@@ -0,0 +1,99 @@
+package at_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rehiy/modem/at"
+)
+
+func TestPhonebookCharset(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`AT\+CSCS=.*`, "OK")
+	s.Handle(`AT\+CPBW=.*`, "OK")
+	s.Handle(`AT\+CCFC=.*`, "OK")
+	s.Handle(`AT\+CPBR=1,3`,
+		// UCS2 编码的号码、按原样返回的号码、偶数位的纯数字号码
+		`+CPBR: 1,"002B0038003600310030003000380036",145,"0041006C006900630065"`,
+		`+CPBR: 2,"10086",129,"0042006F0062"`,
+		`+CPBR: 3,"10086000",129,"0043"`,
+		"OK",
+	)
+
+	if err := d.SetCharset(at.CharsetUCS2); err != nil {
+		t.Fatalf("SetCharset: %v", err)
+	}
+
+	if err := d.WritePhonebook(at.PhonebookEntry{Number: "+8610086", Name: "Alice"}); err != nil {
+		t.Fatalf("WritePhonebook: %v", err)
+	}
+	fwd := at.CallForward{Number: "+8610086"}
+	if err := d.RegisterCallForward(at.ForwardBusy, fwd); err != nil {
+		t.Fatalf("RegisterCallForward: %v", err)
+	}
+	history := strings.Join(s.History(), "\n")
+	for _, want := range []string{
+		`AT+CPBW=,"002B0038003600310030003000380036",145,"0041006C006900630065"`,
+		`AT+CCFC=1,3,"002B0038003600310030003000380036",145`,
+	} {
+		if !strings.Contains(history, want) {
+			t.Fatalf("history = %q, want %s", s.History(), want)
+		}
+	}
+
+	entries, err := d.ReadPhonebook(1, 3)
+	if err != nil {
+		t.Fatalf("ReadPhonebook: %v", err)
+	}
+	want := []string{"+8610086", "10086", "10086000"}
+	if len(entries) != len(want) {
+		t.Fatalf("ReadPhonebook = %+v", entries)
+	}
+	for i, entry := range entries {
+		if entry.Number != want[i] {
+			t.Errorf("entry %d number = %q, want %q", i, entry.Number, want[i])
+		}
+	}
+	if entries[0].Name != "Alice" {
+		t.Errorf("entry 0 name = %q", entries[0].Name)
+	}
+}
+
+func TestUnquotableParameters(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`AT\+CSCS=.*`, "OK")
+	s.Handle(`AT\+CPBW=.*`, "OK")
+
+	// 非十六进制字符集下参数中的引号和换行无法放入字符串常量，发送前返回错误
+	calls := map[string]func() error{
+		"WritePhonebook":      func() error { return d.WritePhonebook(at.PhonebookEntry{Number: "10086", Name: `A"B`}) },
+		"FindPhonebook":       func() error { _, err := d.FindPhonebook("A\rB"); return err },
+		"SendSMSText":         func() error { return d.SendSMSText(`10086"`, "hello") },
+		"RegisterCallForward": func() error { return d.RegisterCallForward(at.ForwardBusy, at.CallForward{Number: "100\n86"}) },
+		"EnterPIN":            func() error { return d.EnterPIN(`12"4`) },
+		"EnterPUK":            func() error { return d.EnterPUK("12345678", "12\r4") },
+		"ChangePIN":           func() error { return d.ChangePIN("1234", `"`) },
+		"SetPINLock":          func() error { return d.SetPINLock(true, "1\n34") },
+	}
+	for name, call := range calls {
+		count := len(s.History())
+		if err := call(); err == nil || !strings.Contains(err.Error(), "quotes or line breaks") {
+			t.Errorf("%s: err = %v", name, err)
+		}
+		if sent := s.History()[count:]; len(sent) != 0 {
+			t.Errorf("%s: sent %q", name, sent)
+		}
+	}
+
+	// UCS2 字符集下编码后不含引号
+	if err := d.SetCharset(at.CharsetUCS2); err != nil {
+		t.Fatalf("SetCharset: %v", err)
+	}
+	if err := d.WritePhonebook(at.PhonebookEntry{Number: "10086", Name: `A"B`}); err != nil {
+		t.Fatalf("WritePhonebook: %v", err)
+	}
+	history := s.History()
+	if cmd := history[len(history)-1]; cmd != `AT+CPBW=,"00310030003000380036",129,"004100220042"` {
+		t.Fatalf("WritePhonebook sent %q", cmd)
+	}
+}
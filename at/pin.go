@@ -0,0 +1,240 @@
+package at
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SIMState SIM 卡锁定状态（+CPIN）
+type SIMState string
+
+const (
+	SIMReady        SIMState = "READY"       // 无需密码
+	SIMPIN          SIMState = "SIM PIN"     // 需要 PIN
+	SIMPUK          SIMState = "SIM PUK"     // PIN 已锁定，需要 PUK
+	SIMPIN2         SIMState = "SIM PIN2"    // 需要 PIN2
+	SIMPUK2         SIMState = "SIM PUK2"    // 需要 PUK2
+	SIMPhoneSIMPIN  SIMState = "PH-SIM PIN"  // 需要机卡锁密码
+	SIMPhoneNetPIN  SIMState = "PH-NET PIN"  // 需要网络锁密码
+	SIMPhoneNetPUK  SIMState = "PH-NET PUK"  // 需要网络锁解锁码
+	SIMPhoneSPPIN   SIMState = "PH-SP PIN"   // 需要运营商锁密码
+	SIMPhoneCorpPIN SIMState = "PH-CORP PIN" // 需要企业锁密码
+)
+
+// Ready 返回 SIM 卡是否已解锁
+func (s SIMState) Ready() bool {
+	return s == SIMReady
+}
+
+// PINRetries 密码剩余尝试次数，模块未上报时为 -1
+type PINRetries struct {
+	PIN  int `json:"pin"`  // PIN 剩余次数
+	PUK  int `json:"puk"`  // PUK 剩余次数
+	PIN2 int `json:"pin2"` // PIN2 剩余次数
+	PUK2 int `json:"puk2"` // PUK2 剩余次数
+}
+
+// SIMStatus 查询 SIM 卡锁定状态
+//
+// 未插卡时返回的错误可用 errors.Is(err, ErrSIMNotInserted) 判断，包括 +CME ERROR: 10、
+// +CMS ERROR: 310 以及部分模块的 +CPIN: NOT INSERTED。
+func (m *Device) SIMStatus() (SIMState, error) {
+	return m.SIMStatusContext(context.Background())
+}
+
+// SIMStatusContext 查询 SIM 卡锁定状态
+func (m *Device) SIMStatusContext(ctx context.Context) (SIMState, error) {
+	resp, err := m.SendCommandResponseContext(ctx, m.commands.PIN+"?")
+	if err != nil {
+		return "", err
+	}
+
+	// 格式: +CPIN: READY 或 +CPIN: SIM PIN
+	if param, ok := resp.Params("+CPIN"); ok && len(param) >= 1 {
+		state := SIMState(strings.ToUpper(param.String(0)))
+		switch state {
+		case "NOT INSERTED":
+			return "", ErrSIMNotInserted
+		case SIMReady:
+			// SIM 卡已解锁（如手动输入了密码），允许再次自动解锁
+			m.pinRejected.Store(false)
+		}
+		return state, nil
+	}
+
+	return "", fmt.Errorf("failed to parse SIM status")
+}
+
+// EnterPIN 输入 PIN 解锁 SIM 卡
+func (m *Device) EnterPIN(pin string) error {
+	return m.EnterPINContext(context.Background(), pin)
+}
+
+// EnterPINContext 输入 PIN 解锁 SIM 卡
+func (m *Device) EnterPINContext(ctx context.Context, pin string) error {
+	pin, err := quoteString(pin)
+	if err != nil {
+		return err
+	}
+	cmd := fmt.Sprintf(`%s=%s`, m.commands.PIN, pin)
+	if err := m.SendCommandExpectContext(ctx, cmd, "OK"); err != nil {
+		return err
+	}
+	m.pinRejected.Store(false)
+	return nil
+}
+
+// EnterPUK 输入 PUK 解锁 SIM 卡并设置新的 PIN
+func (m *Device) EnterPUK(puk, newPIN string) error {
+	return m.EnterPUKContext(context.Background(), puk, newPIN)
+}
+
+// EnterPUKContext 输入 PUK 解锁 SIM 卡并设置新的 PIN
+func (m *Device) EnterPUKContext(ctx context.Context, puk, newPIN string) error {
+	puk, err := quoteString(puk)
+	if err != nil {
+		return err
+	}
+	newPIN, err = quoteString(newPIN)
+	if err != nil {
+		return err
+	}
+	cmd := fmt.Sprintf(`%s=%s,%s`, m.commands.PIN, puk, newPIN)
+	if err := m.SendCommandExpectContext(ctx, cmd, "OK"); err != nil {
+		return err
+	}
+	m.pinRejected.Store(false)
+	return nil
+}
+
+// ChangePIN 修改 PIN，需已启用 PIN 锁
+func (m *Device) ChangePIN(oldPIN, newPIN string) error {
+	return m.ChangePINContext(context.Background(), oldPIN, newPIN)
+}
+
+// ChangePINContext 修改 PIN
+func (m *Device) ChangePINContext(ctx context.Context, oldPIN, newPIN string) error {
+	oldPIN, err := quoteString(oldPIN)
+	if err != nil {
+		return err
+	}
+	newPIN, err = quoteString(newPIN)
+	if err != nil {
+		return err
+	}
+	cmd := fmt.Sprintf(`%s="SC",%s,%s`, m.commands.ChangePassword, oldPIN, newPIN)
+	return m.SendCommandExpectContext(ctx, cmd, "OK")
+}
+
+// GetPINLock 查询是否启用了 PIN 锁（开机需输入 PIN）
+func (m *Device) GetPINLock() (bool, error) {
+	return m.GetPINLockContext(context.Background())
+}
+
+// GetPINLockContext 查询是否启用了 PIN 锁
+func (m *Device) GetPINLockContext(ctx context.Context) (bool, error) {
+	resp, err := m.SendCommandResponseContext(ctx, m.commands.FacilityLock+`="SC",2`)
+	if err != nil {
+		return false, err
+	}
+
+	// 格式: +CLCK: 1
+	if param, ok := resp.Params("+CLCK"); ok && len(param) >= 1 {
+		status, _ := param.Int(0)
+		return status == 1, nil
+	}
+
+	return false, fmt.Errorf("failed to parse PIN lock status")
+}
+
+// SetPINLock 启用或停用 PIN 锁，需要当前 PIN
+func (m *Device) SetPINLock(enable bool, pin string) error {
+	return m.SetPINLockContext(context.Background(), enable, pin)
+}
+
+// SetPINLockContext 启用或停用 PIN 锁
+func (m *Device) SetPINLockContext(ctx context.Context, enable bool, pin string) error {
+	mode := 0
+	if enable {
+		mode = 1
+	}
+	pin, err := quoteString(pin)
+	if err != nil {
+		return err
+	}
+	cmd := fmt.Sprintf(`%s="SC",%d,%s`, m.commands.FacilityLock, mode, pin)
+	return m.SendCommandExpectContext(ctx, cmd, "OK")
+}
+
+// GetPINRetries 查询各密码的剩余尝试次数（AT+CPINR），不支持的模块返回错误
+func (m *Device) GetPINRetries() (PINRetries, error) {
+	return m.GetPINRetriesContext(context.Background())
+}
+
+// GetPINRetriesContext 查询各密码的剩余尝试次数
+func (m *Device) GetPINRetriesContext(ctx context.Context) (PINRetries, error) {
+	resp, err := m.SendCommandResponseContext(ctx, m.commands.PINRetries)
+	if err != nil {
+		return PINRetries{}, err
+	}
+
+	retries := PINRetries{PIN: -1, PUK: -1, PIN2: -1, PUK2: -1}
+	list := resp.All("+CPINR")
+	for _, param := range list {
+		// 格式: +CPINR: SIM PIN,3,3
+		n, ok := param.Int(1)
+		if !ok {
+			continue
+		}
+		switch SIMState(strings.ToUpper(param.String(0))) {
+		case SIMPIN:
+			retries.PIN = n
+		case SIMPUK:
+			retries.PUK = n
+		case SIMPIN2:
+			retries.PIN2 = n
+		case SIMPUK2:
+			retries.PUK2 = n
+		}
+	}
+	if len(list) == 0 {
+		return retries, fmt.Errorf("failed to parse PIN retries")
+	}
+	return retries, nil
+}
+
+// unlockSIM 使用 Config.SIMPIN 和 Config.SIMPUK 自动解锁 SIM 卡
+//
+// 密码被拒绝后不再自动尝试，避免反复输错导致 SIM 卡被锁死；
+// 之后通过 EnterPIN/EnterPUK 解锁成功，或 SIMStatus 查询到 READY 时恢复自动解锁。
+func (m *Device) unlockSIM(ctx context.Context) error {
+	if m.simPIN == "" || m.pinRejected.Load() {
+		return nil
+	}
+
+	state, err := m.SIMStatusContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch state {
+	case SIMPIN:
+		m.printf("unlocking SIM with PIN")
+		err = m.EnterPINContext(ctx, m.simPIN)
+	case SIMPUK:
+		if m.simPUK == "" {
+			return fmt.Errorf("SIM PUK required")
+		}
+		m.printf("unlocking SIM with PUK")
+		err = m.EnterPUKContext(ctx, m.simPUK, m.simPIN)
+	default:
+		return nil
+	}
+
+	if errors.Is(err, ErrBadPassword) {
+		m.pinRejected.Store(true)
+	}
+	return err
+}
@@ -0,0 +1,66 @@
+package at_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rehiy/modem/at"
+	"github.com/rehiy/modem/port/sim"
+)
+
+// countCommands 返回模拟器收到的以 prefix 开头的命令数
+func countCommands(history []string, prefix string) int {
+	n := 0
+	for _, cmd := range history {
+		if strings.HasPrefix(cmd, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestUnlockSIMAfterRejected(t *testing.T) {
+	d, s := newSimDevice(t, &at.Config{SIMPIN: "1234"})
+	s.Handle(`AT\+CPIN\?`, "+CPIN: SIM PIN", "OK")
+	s.AddRule(sim.Rule{Pattern: `AT\+CPIN="1234"`, Responses: []string{"+CME ERROR: 16"}, Times: 1})
+
+	if err := d.Init(); !errors.Is(err, at.ErrBadPassword) {
+		t.Fatalf("Init: err = %v, want ErrBadPassword", err)
+	}
+	// 密码被拒绝后不再自动尝试
+	if err := d.Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if n := countCommands(s.History(), `AT+CPIN="1234"`); n != 1 {
+		t.Fatalf("PIN entered %d times, want 1", n)
+	}
+
+	// 手动解锁成功后恢复自动解锁
+	s.Handle(`AT\+CPIN="4321"`, "OK")
+	if err := d.EnterPIN("4321"); err != nil {
+		t.Fatalf("EnterPIN: %v", err)
+	}
+	s.Handle(`AT\+CPIN="1234"`, "OK")
+	if err := d.Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if n := countCommands(s.History(), `AT+CPIN="1234"`); n != 2 {
+		t.Fatalf("PIN entered %d times, want 2", n)
+	}
+}
+
+func TestSIMNotInserted(t *testing.T) {
+	for _, responses := range [][]string{
+		{"+CME ERROR: 10"},
+		{"+CMS ERROR: 310"},
+		{"+CPIN: NOT INSERTED", "OK"},
+	} {
+		d, s := newSimDevice(t, nil)
+		s.Handle(`AT\+CPIN\?`, responses...)
+
+		if _, err := d.SIMStatus(); !errors.Is(err, at.ErrSIMNotInserted) {
+			t.Errorf("%q: err = %v, want ErrSIMNotInserted", responses, err)
+		}
+	}
+}
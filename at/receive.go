@@ -0,0 +1,188 @@
+package at
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rehiy/modem/sms"
+	"github.com/rehiy/modem/sms/tpdu"
+)
+
+// SMSReceiverConfig 短信接收服务配置
+type SMSReceiverConfig struct {
+	ReassemblyTimeout time.Duration // 长短信分片重组超时，默认 10 分钟
+	Delete            bool          // 完整短信投递后、或重组超时的分片处理后从存储中删除
+	OnExpired         func(SMS)     // 重组超时时投递已收到的部分内容，为 nil 时丢弃
+}
+
+// SMSReceiver 短信接收服务
+type SMSReceiver struct {
+	device    *Device
+	config    SMSReceiverConfig
+	handler   func(SMS)
+	collector *sms.Collector
+	indices   map[string][]int // 按长短信分组记录的存储索引
+	mu        sync.Mutex       // 保护 indices
+	cancel    func()           // 取消通知订阅
+	done      chan struct{}    // 接收协程已退出
+}
+
+// ReceiveSMS 启动短信接收服务
+//
+// 服务监听 +CMTI（短信已存储）和 +CMT（短信直接推送）通知，读取并重组长短信，
+// 将完整短信依次交给 handler 处理。handler 在接收协程中调用，可以在其中发送命令。
+func (m *Device) ReceiveSMS(handler func(SMS), config *SMSReceiverConfig) *SMSReceiver {
+	if config == nil {
+		config = &SMSReceiverConfig{}
+	}
+	if config.ReassemblyTimeout == 0 {
+		config.ReassemblyTimeout = 10 * time.Minute
+	}
+
+	r := &SMSReceiver{
+		device:  m,
+		config:  *config,
+		handler: handler,
+		indices: map[string][]int{},
+		done:    make(chan struct{}),
+	}
+	r.collector = sms.NewCollector(sms.WithReassemblyTimeout(config.ReassemblyTimeout, r.expire))
+
+	// 通知不受 Config.EventPolicy 影响，突发到达时不会丢失
+	events, cancel := m.subscribeQueued(m.notifications.SMSReady, m.notifications.SMSContent)
+	r.cancel = cancel
+
+	go r.run(events)
+
+	return r
+}
+
+// Stop 停止短信接收服务
+func (r *SMSReceiver) Stop() {
+	r.cancel()
+	<-r.done
+	r.collector.Close()
+}
+
+// run 按顺序处理短信通知
+func (r *SMSReceiver) run(events <-chan Event) {
+	defer close(r.done)
+
+	for event := range events {
+		switch data := event.Data.(type) {
+		case SMSReadyEvent:
+			if r.device.smsMode.Load() == 1 {
+				msg, err := r.device.ReadSMSText(data.Index)
+				if err != nil {
+					r.device.printf("read sms %d error: %v", data.Index, err)
+					continue
+				}
+				r.deliver(msg)
+				continue
+			}
+			item, err := r.device.ReadSMSPdu(data.Index)
+			if err != nil {
+				r.device.printf("read sms %d error: %v", data.Index, err)
+				continue
+			}
+			r.collect(item.TPDU, item.Index, smsStatus(item.Status))
+
+		case SMSContentEvent:
+			// 文本模式不含分段信息，直接投递
+			if data.TPDU == nil {
+				r.deliver(SMS{
+					PhoneNumber: data.Number,
+					Text:        data.Text,
+					Time:        data.Time,
+					Index:       -1,
+					Status:      SMSStatusUnread,
+				})
+				continue
+			}
+			r.collect(data.TPDU, -1, SMSStatusUnread)
+		}
+	}
+}
+
+// collect 收集短信分片，集齐后投递
+func (r *SMSReceiver) collect(pdu *tpdu.TPDU, index int, status string) {
+	key := smsKey(pdu)
+	if index >= 0 {
+		r.mu.Lock()
+		r.indices[key] = append(r.indices[key], index)
+		r.mu.Unlock()
+	}
+
+	segments, err := r.collector.Collect(*pdu)
+	if err != nil {
+		r.device.printf("collect sms %d error: %v", index, err)
+		return
+	}
+	if len(segments) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	indices := r.indices[key]
+	delete(r.indices, key)
+	r.mu.Unlock()
+
+	msg, err := newSMS(segments, indices, status)
+	if err != nil {
+		r.device.printf("decode sms error: %v", err)
+		return
+	}
+	r.deliver(msg)
+}
+
+// deliver 投递完整短信，按配置从存储中删除
+func (r *SMSReceiver) deliver(msg SMS) {
+	r.handler(msg)
+
+	if r.config.Delete && len(msg.Indices) > 0 {
+		if err := r.device.DeleteSMS(msg.Indices); err != nil {
+			r.device.printf("delete sms %v error: %v", msg.Indices, err)
+		}
+	}
+}
+
+// expire 处理重组超时的长短信
+func (r *SMSReceiver) expire(segments []*tpdu.TPDU) {
+	received := []*tpdu.TPDU{}
+	for _, s := range segments {
+		if s != nil {
+			received = append(received, s)
+		}
+	}
+	if len(received) == 0 {
+		return
+	}
+
+	key := smsKey(received[0])
+	r.mu.Lock()
+	indices := r.indices[key]
+	delete(r.indices, key)
+	r.mu.Unlock()
+
+	if r.config.OnExpired == nil {
+		r.device.printf("sms reassembly expired, discarding %d of %d segments", len(received), len(segments))
+	} else if msg, err := newSMS(received, indices, ""); err != nil {
+		r.device.printf("decode expired sms error: %v", err)
+	} else {
+		r.config.OnExpired(msg)
+	}
+
+	// 已超时的分片不会再参与重组，与完整短信一样按配置删除
+	if r.config.Delete && len(indices) > 0 {
+		if err := r.device.DeleteSMS(indices); err != nil {
+			r.device.printf("delete sms %v error: %v", indices, err)
+		}
+	}
+}
+
+// smsKey 长短信分组标识
+func smsKey(pdu *tpdu.TPDU) string {
+	segments, _, mref, _ := pdu.ConcatInfo()
+	return fmt.Sprintf("%s:%d:%d", pdu.OA.Number(), mref, segments)
+}
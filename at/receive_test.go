@@ -0,0 +1,150 @@
+package at_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rehiy/modem/at"
+	"github.com/rehiy/modem/port/sim"
+	"github.com/rehiy/modem/sms"
+	"github.com/rehiy/modem/sms/pdumode"
+)
+
+// deliverPDUs 将短信编码为带空 SMSC 地址的 SMS-DELIVER PDU，长短信返回多个分片
+func deliverPDUs(t *testing.T, number, text string) []string {
+	t.Helper()
+
+	tpdus, err := sms.NewEncoder(sms.AsDeliver, sms.From(number)).Encode([]byte(text))
+	if err != nil {
+		t.Fatalf("encode sms: %v", err)
+	}
+	list := []string{}
+	for _, p := range tpdus {
+		b, err := p.MarshalBinary()
+		if err != nil {
+			t.Fatalf("marshal tpdu: %v", err)
+		}
+		pduHex, err := (&pdumode.PDU{TPDU: b}).MarshalHexString()
+		if err != nil {
+			t.Fatalf("marshal pdu: %v", err)
+		}
+		list = append(list, pduHex)
+	}
+	return list
+}
+
+// waitDeleted 等待模拟器收到 AT+CMGD 命令并返回这些命令
+func waitDeleted(s *sim.Modem) []string {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		list := []string{}
+		for _, cmd := range s.History() {
+			if strings.HasPrefix(cmd, "AT+CMGD=") {
+				list = append(list, cmd)
+			}
+		}
+		if len(list) > 0 || time.Now().After(deadline) {
+			return list
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReceiveSMSStatus(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	index := s.StoreSMS(sim.StatUnread, deliverPDU)
+
+	received := make(chan at.SMS, 1)
+	receiver := d.ReceiveSMS(func(msg at.SMS) { received <- msg }, &at.SMSReceiverConfig{Delete: true})
+	defer receiver.Stop()
+
+	s.URC(fmt.Sprintf(`+CMTI: "SM",%d`, index))
+
+	select {
+	case msg := <-received:
+		if msg.Text != "hellohello" || msg.Status != at.SMSStatusUnread {
+			t.Fatalf("received %+v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("sms not received")
+	}
+
+	if cmds := waitDeleted(s); len(cmds) != 1 || cmds[0] != fmt.Sprintf("AT+CMGD=%d", index) {
+		t.Fatalf("deleted %q", cmds)
+	}
+
+	// 列表与通知使用相同的状态表示
+	s.StoreSMS(sim.StatRead, deliverPDU)
+	list, err := d.ListSMSPdu(sim.StatAll)
+	if err != nil || len(list) != 1 || list[0].Status != at.SMSStatusRead {
+		t.Fatalf("ListSMSPdu = %+v, %v", list, err)
+	}
+}
+
+func TestReceiveSMSExpired(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	pdus := deliverPDUs(t, "+8613800138000", strings.Repeat("0123456789", 20))
+	if len(pdus) < 2 {
+		t.Fatalf("encoded %d segments", len(pdus))
+	}
+	index := s.StoreSMS(sim.StatUnread, pdus[0])
+
+	expired := make(chan at.SMS, 1)
+	receiver := d.ReceiveSMS(func(msg at.SMS) {
+		t.Errorf("unexpected sms %+v", msg)
+	}, &at.SMSReceiverConfig{
+		ReassemblyTimeout: 50 * time.Millisecond,
+		Delete:            true,
+		OnExpired:         func(msg at.SMS) { expired <- msg },
+	})
+	defer receiver.Stop()
+
+	s.URC(fmt.Sprintf(`+CMTI: "SM",%d`, index))
+
+	select {
+	case msg := <-expired:
+		if len(msg.Indices) != 1 || msg.Indices[0] != index {
+			t.Fatalf("expired %+v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("fragment not expired")
+	}
+
+	// 超时的分片处理后同样从存储中删除
+	if cmds := waitDeleted(s); len(cmds) != 1 || cmds[0] != fmt.Sprintf("AT+CMGD=%d", index) {
+		t.Fatalf("deleted %q", cmds)
+	}
+}
+
+func TestReceiveSMSBurst(t *testing.T) {
+	d, s := newSimDevice(t, &at.Config{EventBuffer: 2})
+
+	release := make(chan struct{})
+	received := make(chan at.SMS, 64)
+	receiver := d.ReceiveSMS(func(msg at.SMS) {
+		<-release
+		received <- msg
+	}, nil)
+	defer receiver.Stop()
+
+	// 处理函数阻塞期间到达的通知超过订阅缓冲，全部投递不丢失
+	const count = 10
+	for i := 0; i < count; i++ {
+		s.DeliverSMS(deliverPDUs(t, "+8613800138000", fmt.Sprintf("message %d", i))[0])
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+
+	for i := 0; i < count; i++ {
+		select {
+		case msg := <-received:
+			if want := fmt.Sprintf("message %d", i); msg.Text != want {
+				t.Fatalf("received %q, want %q", msg.Text, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %d of %d messages", i, count)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package at
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DeliveryState 短信投递状态
+type DeliveryState int
+
+const (
+	DeliveryPending   DeliveryState = iota // 等待状态报告或短信中心仍在尝试投递
+	DeliveryDelivered                      // 已投递
+	DeliveryFailed                         // 投递失败
+)
+
+func (s DeliveryState) String() string {
+	switch s {
+	case DeliveryPending:
+		return "pending"
+	case DeliveryDelivered:
+		return "delivered"
+	case DeliveryFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("unknown(%d)", int(s))
+}
+
+// StatusReport 单个分段的状态报告
+type StatusReport struct {
+	MR     int           `json:"mr"`     // 消息参考号
+	ST     int           `json:"st"`     // TP-ST 状态
+	State  DeliveryState `json:"state"`  // 投递状态
+	Reason string        `json:"reason"` // TP-ST 状态说明
+	Time   time.Time     `json:"time"`   // 收到报告的时间
+}
+
+// deliveryState 根据 TP-ST 判断投递状态（3GPP TS 23.040 9.2.3.15）
+func deliveryState(st int) DeliveryState {
+	switch {
+	case st < 0x20:
+		return DeliveryDelivered
+	case st < 0x40:
+		return DeliveryPending // 临时错误，短信中心仍在尝试
+	default:
+		return DeliveryFailed // 永久错误，或临时错误且短信中心已停止尝试
+	}
+}
+
+// statusReasons TP-ST 状态说明
+var statusReasons = map[int]string{
+	0x00: "received by the SME",
+	0x01: "forwarded by the SC to the SME but delivery unconfirmed",
+	0x02: "replaced by the SC",
+	0x20: "congestion",
+	0x21: "SME busy",
+	0x22: "no response from SME",
+	0x23: "service rejected",
+	0x24: "quality of service not available",
+	0x25: "error in SME",
+	0x40: "remote procedure error",
+	0x41: "incompatible destination",
+	0x42: "connection rejected by SME",
+	0x43: "not obtainable",
+	0x44: "quality of service not available",
+	0x45: "no interworking available",
+	0x46: "SM validity period expired",
+	0x47: "SM deleted by originating SME",
+	0x48: "SM deleted by SC administration",
+	0x49: "SM does not exist",
+	0x60: "congestion, SC stopped retrying",
+	0x61: "SME busy, SC stopped retrying",
+	0x62: "no response from SME, SC stopped retrying",
+	0x63: "service rejected, SC stopped retrying",
+	0x64: "quality of service not available, SC stopped retrying",
+	0x65: "error in SME, SC stopped retrying",
+}
+
+// statusReason 返回 TP-ST 状态说明
+func statusReason(st int) string {
+	if reason, ok := statusReasons[st]; ok {
+		return reason
+	}
+	return fmt.Sprintf("status 0x%02X", st)
+}
+
+// SentSMS 请求了状态报告的已发送短信
+type SentSMS struct {
+	Number  string               `json:"number"` // 接收方号码
+	Refs    []int                `json:"refs"`   // 各分段的消息参考号
+	reports map[int]StatusReport // 按消息参考号记录的状态报告
+	sealed  bool                 // 是否已发送完全部分段
+	final   bool                 // 是否已有最终状态
+	mu      sync.Mutex           // 保护 Refs、reports、sealed 和 final
+	done    chan struct{}        // 全部分段投递完成或失败时关闭
+}
+
+// State 返回整体投递状态：任一分段失败即失败，全部投递才算已投递
+func (s *SentSMS) State() DeliveryState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := DeliveryDelivered
+	for _, ref := range s.Refs {
+		report, ok := s.reports[ref]
+		switch {
+		case ok && report.State == DeliveryFailed:
+			return DeliveryFailed
+		case !ok || report.State == DeliveryPending:
+			state = DeliveryPending
+		}
+	}
+	return state
+}
+
+// Reports 返回已收到的状态报告，按分段顺序排列
+func (s *SentSMS) Reports() []StatusReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reports := []StatusReport{}
+	for _, ref := range s.Refs {
+		if report, ok := s.reports[ref]; ok {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+// Done 返回在全部分段有最终状态时关闭的通道
+func (s *SentSMS) Done() <-chan struct{} {
+	return s.done
+}
+
+// Wait 等待全部分段有最终状态或 ctx 结束，返回整体投递状态
+func (s *SentSMS) Wait(ctx context.Context) (DeliveryState, error) {
+	select {
+	case <-s.done:
+		return s.State(), nil
+	case <-ctx.Done():
+		return s.State(), ctx.Err()
+	}
+}
+
+// update 记录状态报告，返回该短信是否已有最终状态
+func (s *SentSMS) update(report StatusReport) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.final {
+		return true
+	}
+
+	s.reports[report.MR] = report
+	return s.checkFinal()
+}
+
+// seal 标记全部分段已发送，返回该短信是否已有最终状态
+func (s *SentSMS) seal() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sealed = true
+	return s.checkFinal()
+}
+
+// checkFinal 检查是否全部分段都有最终状态，调用方需持有锁
+func (s *SentSMS) checkFinal() bool {
+	if s.final {
+		return true
+	}
+	if !s.sealed {
+		return false
+	}
+	for _, ref := range s.Refs {
+		if r, ok := s.reports[ref]; !ok || r.State == DeliveryPending {
+			return false
+		}
+	}
+
+	s.final = true
+	close(s.done)
+	return true
+}
+
+// SendSMSPduReport 发送短信并请求状态报告（TP-SRR）
+//
+// 状态报告通过 +CDS 通知送达，需要通过 AT+CNMI 开启（如 AT+CNMI=2,1,0,1）。
+func (m *Device) SendSMSPduReport(number, message string) (*SentSMS, error) {
+	return m.SendSMSPduReportContext(context.Background(), number, message)
+}
+
+// SendSMSPduReportContext 发送短信并请求状态报告
+func (m *Device) SendSMSPduReportContext(ctx context.Context, number, message string) (*SentSMS, error) {
+	m.reportOnce.Do(func() {
+		// 状态报告不受 Config.EventPolicy 影响，突发到达时不会丢失
+		events, _ := m.subscribeQueued(m.notifications.SMSStatusReport)
+		go m.trackReports(events)
+	})
+
+	sent := &SentSMS{
+		Number:  number,
+		Refs:    []int{},
+		reports: map[int]StatusReport{},
+		done:    make(chan struct{}),
+	}
+
+	// 每个分段拿到消息参考号后立即登记，避免状态报告先于登记到达
+	_, err := m.sendSMSPdu(ctx, number, message, true, func(ref int) {
+		sent.mu.Lock()
+		sent.Refs = append(sent.Refs, ref)
+		sent.mu.Unlock()
+
+		m.reportMu.Lock()
+		m.reports[ref] = sent
+		m.reportMu.Unlock()
+	})
+
+	// 已发送的分段仍然跟踪，便于调用方查询部分发送的结果
+	if len(sent.Refs) == 0 {
+		if err == nil {
+			err = fmt.Errorf("no message reference returned")
+		}
+		return nil, err
+	}
+	if sent.seal() {
+		m.forget(sent)
+	}
+	return sent, err
+}
+
+// forget 停止跟踪已有最终状态的短信
+func (m *Device) forget(sent *SentSMS) {
+	m.reportMu.Lock()
+	defer m.reportMu.Unlock()
+
+	for _, ref := range sent.Refs {
+		if m.reports[ref] == sent {
+			delete(m.reports, ref)
+		}
+	}
+}
+
+// trackReports 将状态报告关联到已发送的短信
+func (m *Device) trackReports(events <-chan Event) {
+	for event := range events {
+		data, ok := event.Data.(SMSStatusReportEvent)
+		if !ok {
+			continue
+		}
+
+		m.reportMu.Lock()
+		sent := m.reports[data.MR]
+		m.reportMu.Unlock()
+
+		// 消息参考号会循环使用，同时校验接收方号码
+		if sent == nil || !sameNumber(sent.Number, data.Recipient) {
+			m.printf("unmatched status report: mr=%d recipient=%s", data.MR, data.Recipient)
+			continue
+		}
+
+		report := StatusReport{
+			MR:     data.MR,
+			ST:     data.ST,
+			State:  deliveryState(data.ST),
+			Reason: statusReason(data.ST),
+			Time:   event.Time,
+		}
+		if sent.update(report) {
+			m.forget(sent)
+		}
+	}
+}
+
+// sameNumber 比较两个号码，忽略国际前缀差异
+func sameNumber(a, b string) bool {
+	if a == "" || b == "" {
+		return true
+	}
+	a, b = strings.TrimPrefix(a, "+"), strings.TrimPrefix(b, "+")
+	return strings.HasSuffix(a, b) || strings.HasSuffix(b, a)
+}
@@ -0,0 +1,149 @@
+package at
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Response 命令的结构化响应
+type Response struct {
+	Lines    []string      // 信息行，不含命令回显和最终结果码
+	Final    string        // 最终结果码，如 "OK"、"+CME ERROR: 10"，超时或取消时为空
+	Err      error         // 命令错误，与 SendCommandResponse 返回的错误相同
+	Duration time.Duration // 从写入命令到命令结束的耗时，不含等待命令锁的时间
+}
+
+// OK 返回命令是否成功完成
+func (r *Response) OK() bool {
+	return r.Err == nil && r.Final != ""
+}
+
+// Line 返回第一条以 label 开头的信息行，如 Line("+CSQ")
+func (r *Response) Line(label string) (string, bool) {
+	for _, line := range r.Lines {
+		if l, _ := parseParam(line); l == label {
+			return line, true
+		}
+	}
+	return "", false
+}
+
+// Params 返回第一条以 label 开头的信息行的参数
+func (r *Response) Params(label string) (Params, bool) {
+	for _, line := range r.Lines {
+		if !strings.Contains(line, ":") {
+			continue
+		}
+		if l, values, _ := ParseLine(line); l == label {
+			return Params(values), true
+		}
+	}
+	return nil, false
+}
+
+// All 返回所有以 label 开头的信息行的参数，用于 AT+CMGL 等多行列表
+func (r *Response) All(label string) []Params {
+	list := []Params{}
+	for _, line := range r.Lines {
+		if !strings.Contains(line, ":") {
+			continue
+		}
+		if l, values, _ := ParseLine(line); l == label {
+			list = append(list, Params(values))
+		}
+	}
+	return list
+}
+
+// Values 返回第一条以 label 开头的信息行按响应语法解析的参数
+func (r *Response) Values(label string) ([]Value, bool) {
+	for _, line := range r.Lines {
+		if !strings.Contains(line, ":") {
+			continue
+		}
+		if l, values, _ := ParseLine(line); l == label {
+			return values, true
+		}
+	}
+	return nil, false
+}
+
+// Params 信息行的参数，按位置索引，保留解析时的参数类型
+//
+// 缺省的参数（如 "+CNUM: ,\"+8613800138000\",129" 的第一个参数）为 ValueEmpty，
+// 括号内的列表或范围（如 "(0-4)"、"(1,2)"）作为一个参数。
+type Params []Value
+
+// Has 返回参数是否存在且不为空
+func (p Params) Has(i int) bool {
+	return p.Value(i).Text != ""
+}
+
+// String 返回参数文本，字符串已去除引号，不存在时返回空字符串
+func (p Params) String(i int) string {
+	return p.Value(i).Text
+}
+
+// Int 返回十进制整数参数，不存在或格式错误时 ok 为 false
+func (p Params) Int(i int) (int, bool) {
+	v, err := strconv.Atoi(p.Value(i).Text)
+	return v, err == nil
+}
+
+// Hex 返回十六进制整数参数（如 LAC "1A2B"），不存在或格式错误时 ok 为 false
+func (p Params) Hex(i int) (int, bool) {
+	v, err := strconv.ParseInt(p.Value(i).Text, 16, 64)
+	return int(v), err == nil
+}
+
+// List 返回括号内的列表参数，如 "(1,2,5)" 返回 1、2、5 三个参数；不带括号时作为单元素列表
+func (p Params) List(i int) Params {
+	if i < 0 || i >= len(p) {
+		return nil
+	}
+	return Params(p[i].List())
+}
+
+// Range 返回范围参数，如 "0-4" 或 "(0-4)"；单个数值时上下限相同
+func (p Params) Range(i int) (int, int, bool) {
+	return p.Value(i).Range()
+}
+
+// Value 返回参数的解析结果，可取得类型和列表的嵌套结构，不存在时返回 ValueEmpty
+func (p Params) Value(i int) Value {
+	if i < 0 || i >= len(p) {
+		return Value{Kind: ValueEmpty}
+	}
+	return p[i]
+}
+
+// Map 按位置返回参数文本，与 Event.Param 的格式相同
+func (p Params) Map() map[int]string {
+	return newParams(p)
+}
+
+// SendCommandResponse 发送命令并返回结构化响应
+//
+// 返回的 *Response 不为 nil，命令失败时其 Err 与返回的错误相同，Lines 保留已收到的信息行。
+func (m *Device) SendCommandResponse(cmd string) (*Response, error) {
+	return m.SendCommandResponseContext(context.Background(), cmd)
+}
+
+// SendCommandResponseContext 发送命令并返回结构化响应，支持通过 ctx 取消
+func (m *Device) SendCommandResponseContext(ctx context.Context, cmd string) (*Response, error) {
+	if err := m.acquire(ctx); err != nil {
+		return &Response{Lines: []string{}, Err: err}, err
+	}
+
+	start := time.Now()
+	responses, detached, err := m.exchange(ctx, cmd, cmd, m.commandTimeout(cmd))
+	if !detached {
+		m.release()
+	}
+
+	resp := &Response{Err: err, Duration: time.Since(start)}
+	resp.Lines, resp.Final = m.splitFinal(responses)
+	return resp, err
+}
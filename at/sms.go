@@ -1,12 +1,14 @@
 package at
 
 import (
+	"context"
 	"fmt"
 	"sort"
-	"time"
+	"strconv"
 
 	"github.com/rehiy/modem/sms"
 	"github.com/rehiy/modem/sms/pdumode"
+	"github.com/rehiy/modem/sms/tpdu"
 )
 
 // SMS 短信信息
@@ -14,36 +16,69 @@ type SMS struct {
 	PhoneNumber string `json:"phoneNumber"`
 	Text        string `json:"text"`
 	Time        string `json:"time"`
-	Index       int    `json:"index"`   // 首个分片的索引
+	Index       int    `json:"index"`   // 首个分片的索引，未存储（+CMT 直接推送）时为 -1
 	Indices     []int  `json:"indices"` // 所有分片的索引
-	Status      string `json:"status"`  // 短信状态 [PDU: TEXT, 0: "REC UNREAD", 1: "REC READ", 2: "STO UNSENT", 3: "STO SENT", 4: "ALL"]
+	Status      string `json:"status"`  // 短信状态，PDU 模式的数字状态也转换为文本，如 "REC UNREAD"
+}
+
+// SMSPdu 存储中的单条短信 PDU
+type SMSPdu struct {
+	Index  int        `json:"index"`  // 存储索引
+	Status int        `json:"status"` // 短信状态 [0: "REC UNREAD", 1: "REC READ", 2: "STO UNSENT", 3: "STO SENT"]
+	SMSC   string     `json:"smsc"`   // 短信中心号码
+	TPDU   *tpdu.TPDU `json:"tpdu"`   // 解码后的 TPDU
 }
 
 // SetSMSMode 设置短信模式
 // v [0: PDU 模式, 1: TEXT 模式]
 func (m *Device) SetSMSMode(v int) error {
+	return m.SetSMSModeContext(context.Background(), v)
+}
+
+// SetSMSModeContext 设置短信模式
+func (m *Device) SetSMSModeContext(ctx context.Context, v int) error {
 	cmd := fmt.Sprintf("%s=%d", m.commands.SMSFormat, v)
-	return m.SendCommandExpect(cmd, "OK")
+	if err := m.SendCommandExpectContext(ctx, cmd, "OK"); err != nil {
+		return err
+	}
+	m.smsMode.Store(int32(v))
+	return nil
 }
 
 // SendSMSPdu 发送短信
 func (m *Device) SendSMSPdu(number, message string) error {
+	return m.SendSMSPduContext(context.Background(), number, message)
+}
+
+// SendSMSPduContext 发送短信，取消时尚未发送的分段将不再发送
+func (m *Device) SendSMSPduContext(ctx context.Context, number, message string) error {
+	_, err := m.sendSMSPdu(ctx, number, message, false, nil)
+	return err
+}
+
+// sendSMSPdu 发送短信，返回各分段的消息参考号
+// report 为 true 时请求状态报告，onRef 不为 nil 时在每个分段发送成功后回调其消息参考号
+func (m *Device) sendSMSPdu(ctx context.Context, number, message string, report bool, onRef func(int)) ([]int, error) {
 	tpdus, err := sms.Encode([]byte(message), sms.To(number))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// 临时延长超时
-	rdTimeout := m.timeout
-	m.timeout = time.Second * 60
-	defer func() { m.timeout = rdTimeout }()
+	// 短信内容提交后才真正发送，使用发送命令的超时
+	timeout := m.commandTimeout(m.commands.SendSMS)
 
+	refs := []int{}
 	for _, p := range tpdus {
+		// 请求状态报告
+		if report {
+			p.FirstOctet |= tpdu.FoSRR
+		}
+
 		// 将 TPDU 序列化为字节数组
 		tpduBytes, err := p.MarshalBinary()
 		if err != nil {
 			m.printf("marshal tpdu error: %v", err)
-			return err
+			return refs, err
 		}
 
 		// 使用 pdumode 包装 TPDU 并编码为十六进制
@@ -51,30 +86,41 @@ func (m *Device) SendSMSPdu(number, message string) error {
 		pduHex, err := pdu.MarshalHexString()
 		if err != nil {
 			m.printf("marshal pdu error: %v", err)
-			return err
+			return refs, err
 		}
 
-		// 发送 AT 命令（TPDU 长度不包含 SMSC 部分）
+		// 发送 AT 命令和 PDU 数据（TPDU 长度不包含 SMSC 部分）
 		cmd := fmt.Sprintf("%s=%d", m.commands.SendSMS, len(tpduBytes))
-		if err := m.SendCommandExpect(cmd, ">"); err != nil {
-			m.printf("send sms command error: %v", err)
-			return err
+		responses, err := m.sendWithPrompt(ctx, cmd, pduHex, timeout)
+		if err != nil {
+			m.printf("send sms error: %v", err)
+			return refs, err
 		}
 
-		// 发送 PDU 数据
-		if _, err := m.SendCommand(pduHex + "\x1A"); err != nil {
-			m.printf("send sms response error: %v", err)
-			return err
+		// 格式: +CMGS: <mr>
+		for _, line := range responses {
+			if label, param := parseParam(line); label == "+CMGS" && len(param) >= 1 {
+				ref := parseInt(param[0])
+				refs = append(refs, ref)
+				if onRef != nil {
+					onRef(ref)
+				}
+			}
 		}
 	}
 
-	return nil
+	return refs, nil
 }
 
 // ListSMSPdu 获取短信列表
 func (m *Device) ListSMSPdu(stat int) ([]SMS, error) {
+	return m.ListSMSPduContext(context.Background(), stat)
+}
+
+// ListSMSPduContext 获取短信列表
+func (m *Device) ListSMSPduContext(ctx context.Context, stat int) ([]SMS, error) {
 	cmd := fmt.Sprintf("%s=%d", m.commands.ListSMS, stat)
-	responses, err := m.SendCommand(cmd)
+	responses, err := m.SendCommandContext(ctx, cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -101,30 +147,23 @@ func (m *Device) ListSMSPdu(stat int) ([]SMS, error) {
 		pduHex := responses[i]
 		i++
 
-		// 解析十六进制 PDU
-		pdu, err := pdumode.UnmarshalHexString(pduHex)
+		// 解析 PDU
+		item, err := newSMSPdu(parseInt(param[0]), parseInt(param[1]), pduHex)
 		if err != nil {
 			m.printf("unmarshal pdu error: %v", err)
 			continue
 		}
 
-		// 从 PDU 中解析 TPDU
-		tpduMsg, err := sms.Unmarshal(pdu.TPDU)
-		if err != nil {
-			m.printf("unmarshal tpdu error: %v", err)
-			continue
-		}
-
 		// 记录索引和引用号
-		index := parseInt(param[0])
-		_, _, mref, _ := tpduMsg.ConcatInfo()
+		index := item.Index
+		_, _, mref, _ := item.TPDU.ConcatInfo()
 		if mref == 0 {
 			mref = index
 		}
 		indices[mref] = append(indices[mref], index)
 
 		// 收集短信（长短信自动合并）
-		segments, err := collector.Collect(*tpduMsg)
+		segments, err := collector.Collect(*item.TPDU)
 		if err != nil {
 			m.printf("collect sms %d error: %v", index, err)
 			continue
@@ -132,20 +171,12 @@ func (m *Device) ListSMSPdu(stat int) ([]SMS, error) {
 
 		// 收集到完整短信时解码并添加
 		if len(segments) > 0 {
-			msgBytes, err := sms.Decode(segments)
+			msg, err := newSMS(segments, indices[mref], smsStatus(item.Status))
 			if err != nil {
 				m.printf("decode sms error: %v", err)
 				continue
 			}
-
-			result = append(result, SMS{
-				PhoneNumber: segments[0].OA.Number(),
-				Text:        string(msgBytes),
-				Time:        segments[0].SCTS.Time.Format("2006/01/02 15:04:05"),
-				Index:       indices[mref][0],
-				Indices:     indices[mref],
-				Status:      param[1],
-			})
+			result = append(result, msg)
 			delete(indices, mref)
 		}
 	}
@@ -156,11 +187,94 @@ func (m *Device) ListSMSPdu(stat int) ([]SMS, error) {
 	return result, nil
 }
 
+// ReadSMSPdu 读取指定索引的短信
+func (m *Device) ReadSMSPdu(index int) (SMSPdu, error) {
+	return m.ReadSMSPduContext(context.Background(), index)
+}
+
+// ReadSMSPduContext 读取指定索引的短信
+func (m *Device) ReadSMSPduContext(ctx context.Context, index int) (SMSPdu, error) {
+	cmd := fmt.Sprintf("%s=%d", m.commands.ReadSMS, index)
+	responses, err := m.SendCommandContext(ctx, cmd)
+	if err != nil {
+		return SMSPdu{}, err
+	}
+
+	for i := 0; i < len(responses)-1; i++ {
+		label, param := parseParam(responses[i])
+		// 格式: +CMGR: <stat>,[<alpha>],<length>，下一行为 PDU
+		if label != "+CMGR" || len(param) < 2 {
+			continue
+		}
+		return newSMSPdu(index, parseInt(param[0]), responses[i+1])
+	}
+
+	return SMSPdu{}, fmt.Errorf("no sms found at index %d", index)
+}
+
+// newSMSPdu 解析存储中的短信 PDU
+func newSMSPdu(index, stat int, pduHex string) (SMSPdu, error) {
+	pdu, err := pdumode.UnmarshalHexString(pduHex)
+	if err != nil {
+		return SMSPdu{}, err
+	}
+
+	tpduMsg, err := sms.Unmarshal(pdu.TPDU)
+	if err != nil {
+		return SMSPdu{}, err
+	}
+
+	return SMSPdu{Index: index, Status: stat, SMSC: pdu.SMSC.Number(), TPDU: tpduMsg}, nil
+}
+
+// smsStatus 将 PDU 模式的数字状态转换为文本模式的状态
+func smsStatus(stat int) string {
+	switch stat {
+	case 0:
+		return SMSStatusUnread
+	case 1:
+		return SMSStatusRead
+	case 2:
+		return SMSStatusUnsent
+	case 3:
+		return SMSStatusSent
+	case 4:
+		return SMSStatusAll
+	}
+	return strconv.Itoa(stat)
+}
+
+// newSMS 将完整的短信分片解码为 SMS
+func newSMS(segments []*tpdu.TPDU, indices []int, status string) (SMS, error) {
+	msgBytes, err := sms.Decode(segments)
+	if err != nil {
+		return SMS{}, err
+	}
+
+	msg := SMS{
+		PhoneNumber: segments[0].OA.Number(),
+		Text:        string(msgBytes),
+		Time:        segments[0].SCTS.Time.Format("2006/01/02 15:04:05"),
+		Index:       -1,
+		Indices:     indices,
+		Status:      status,
+	}
+	if len(indices) > 0 {
+		msg.Index = indices[0]
+	}
+	return msg, nil
+}
+
 // DeleteSMS 批量删除指定索引的短信
 func (m *Device) DeleteSMS(indices []int) error {
+	return m.DeleteSMSContext(context.Background(), indices)
+}
+
+// DeleteSMSContext 批量删除指定索引的短信
+func (m *Device) DeleteSMSContext(ctx context.Context, indices []int) error {
 	for _, index := range indices {
 		cmd := fmt.Sprintf("%s=%d", m.commands.DeleteSMS, index)
-		if _, err := m.SendCommand(cmd); err != nil {
+		if _, err := m.SendCommandContext(ctx, cmd); err != nil {
 			return err
 		}
 	}
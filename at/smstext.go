@@ -0,0 +1,206 @@
+package at
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 文本模式短信状态
+const (
+	SMSStatusUnread = "REC UNREAD" // 未读
+	SMSStatusRead   = "REC READ"   // 已读
+	SMSStatusUnsent = "STO UNSENT" // 未发送
+	SMSStatusSent   = "STO SENT"   // 已发送
+	SMSStatusAll    = "ALL"        // 全部
+)
+
+// SendSMSText 以文本模式发送短信（需先 SetSMSMode(1)）
+//
+// 号码和内容按当前 TE 字符集（AT+CSCS）编码；文本模式不做分段，内容长度需满足单条短信限制。
+// GSM 字符集下内容含扩展字符（如 {、}、[、€）时返回错误，需改用 UCS2 字符集或 PDU 模式。
+func (m *Device) SendSMSText(number, message string) error {
+	return m.SendSMSTextContext(context.Background(), number, message)
+}
+
+// SendSMSTextContext 以文本模式发送短信
+func (m *Device) SendSMSTextContext(ctx context.Context, number, message string) error {
+	charset := m.currentCharset()
+	da, err := encodeQuoted(charset, number)
+	if err != nil {
+		return err
+	}
+	text, err := encodeString(charset, message)
+	if err != nil {
+		return err
+	}
+	// 提示符后 ESC 会取消输入、Ctrl-Z 会提前提交；GSM 字符集的扩展字符（如 {、}、[、€）编码后以 ESC 开头
+	if strings.ContainsAny(text, "\x1A\x1B") {
+		return fmt.Errorf("message cannot be sent in %s charset, use UCS2 or PDU mode", charset)
+	}
+
+	// 短信内容提交后才真正发送，使用发送命令的超时
+	cmd := fmt.Sprintf(`%s=%s`, m.commands.SendSMS, da)
+	timeout := m.commandTimeout(m.commands.SendSMS)
+	if _, err := m.sendWithPrompt(ctx, cmd, text, timeout); err != nil {
+		m.printf("send sms error: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ListSMSText 以文本模式获取短信列表
+// stat ["REC UNREAD", "REC READ", "STO UNSENT", "STO SENT", "ALL"]
+func (m *Device) ListSMSText(stat string) ([]SMS, error) {
+	return m.ListSMSTextContext(context.Background(), stat)
+}
+
+// ListSMSTextContext 以文本模式获取短信列表
+func (m *Device) ListSMSTextContext(ctx context.Context, stat string) ([]SMS, error) {
+	cmd := fmt.Sprintf(`%s="%s"`, m.commands.ListSMS, stat)
+	responses, err := m.SendCommandContext(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	charset := m.currentCharset()
+	result := []SMS{}
+
+	// 去掉最终响应
+	lines := responses[:len(responses)-1]
+	for i := 0; i < len(lines); {
+		label, param := parseParam(lines[i])
+		i++
+
+		// 格式: +CMGL: 1,"REC UNREAD","+8613800138000",,"24/01/01,12:00:00+32"
+		if label != "+CMGL" || len(param) < 3 {
+			continue
+		}
+
+		// 正文可能跨多行，直到下一条头部
+		body := []string{}
+		for i < len(lines) && !strings.HasPrefix(lines[i], "+CMGL:") {
+			body = append(body, lines[i])
+			i++
+		}
+
+		index := parseInt(param[0])
+		result = append(result, SMS{
+			PhoneNumber: decodeTextField(charset, param[2]),
+			Text:        decodeTextBody(charset, -1, joinBody(body)),
+			Time:        parseTextTime(param[4]),
+			Index:       index,
+			Indices:     []int{index},
+			Status:      param[1],
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Index > result[j].Index
+	})
+	return result, nil
+}
+
+// ReadSMSText 以文本模式读取指定索引的短信
+func (m *Device) ReadSMSText(index int) (SMS, error) {
+	return m.ReadSMSTextContext(context.Background(), index)
+}
+
+// ReadSMSTextContext 以文本模式读取指定索引的短信
+func (m *Device) ReadSMSTextContext(ctx context.Context, index int) (SMS, error) {
+	cmd := fmt.Sprintf("%s=%d", m.commands.ReadSMS, index)
+	responses, err := m.SendCommandContext(ctx, cmd)
+	if err != nil {
+		return SMS{}, err
+	}
+
+	charset := m.currentCharset()
+	lines := responses[:len(responses)-1]
+	for i, line := range lines {
+		label, param := parseParam(line)
+		// 格式: +CMGR: "REC READ","+8613800138000",,"24/01/01,12:00:00+32"[,<tooa>,<fo>,<pid>,<dcs>,<sca>,<tosca>,<length>]
+		if label != "+CMGR" || len(param) < 2 {
+			continue
+		}
+
+		dcs := -1
+		if v, ok := param[7]; ok && v != "" {
+			dcs = parseInt(v)
+		}
+
+		return SMS{
+			PhoneNumber: decodeTextField(charset, param[1]),
+			Text:        decodeTextBody(charset, dcs, joinBody(lines[i+1:])),
+			Time:        parseTextTime(param[3]),
+			Index:       index,
+			Indices:     []int{index},
+			Status:      param[0],
+		}, nil
+	}
+
+	return SMS{}, fmt.Errorf("no sms found at index %d", index)
+}
+
+// joinBody 拼接正文行，去掉首尾作为行分隔的空行，保留正文中的空行
+func joinBody(lines []string) string {
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// decodeTextField 解码字符串参数，失败时返回原值
+func decodeTextField(charset, s string) string {
+	if text, err := decodeString(charset, s); err == nil {
+		return text
+	}
+	return s
+}
+
+// decodeTextBody 解码文本模式短信正文
+// dcs 已知时按数据编码方案解码（UCS2/8 位数据以十六进制传输），否则按 TE 字符集解码
+func decodeTextBody(charset string, dcs int, body string) string {
+	if dcs >= 0 && dcs&0xC0 == 0 {
+		switch dcs & 0x0C {
+		case 0x08: // UCS2
+			if text, err := decodeUCS2Hex(body); err == nil {
+				return text
+			}
+			return body
+		case 0x04: // 8 位数据
+			if b, err := hex.DecodeString(body); err == nil {
+				return string(b)
+			}
+			return body
+		}
+	}
+	return decodeTextField(charset, body)
+}
+
+// parseTextTime 解析文本模式时间戳 "yy/MM/dd,hh:mm:ss±zz"（zz 单位为 15 分钟）
+func parseTextTime(s string) string {
+	if len(s) < 17 {
+		return s
+	}
+
+	loc := time.Local
+	if len(s) > 17 {
+		if zone, err := strconv.Atoi(s[17:]); err == nil {
+			loc = time.FixedZone("", zone*15*60)
+		}
+	}
+
+	t, err := time.ParseInLocation("06/01/02,15:04:05", s[:17], loc)
+	if err != nil {
+		return s
+	}
+	return t.Format("2006/01/02 15:04:05")
+}
@@ -0,0 +1,65 @@
+package at_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rehiy/modem/at"
+)
+
+func TestSendSMSTextExtensionChars(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`AT\+CSCS=.*`, "OK")
+
+	if err := d.SetSMSMode(1); err != nil {
+		t.Fatalf("SetSMSMode: %v", err)
+	}
+	if err := d.SetCharset(at.CharsetGSM); err != nil {
+		t.Fatalf("SetCharset: %v", err)
+	}
+
+	for _, text := range []string{"{json}", "price 5€", "a[0]"} {
+		if err := d.SendSMSText("+8613800138000", text); err == nil {
+			t.Fatalf("SendSMSText(%q) succeeded", text)
+		}
+	}
+	for _, cmd := range s.History() {
+		if strings.HasPrefix(cmd, "AT+CMGS") {
+			t.Fatalf("prompt command sent: %q", s.History())
+		}
+	}
+}
+
+func TestSMSTextBlankLines(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	// 按 27.005 的格式原样输出：信息行之间只有一个 CRLF，正文中含空行
+	s.HandleFunc(`AT\+CMGL="ALL"`, func(cmd string, match []string) []string {
+		s.Inject("\r\n+CMGL: 1,\"REC READ\",\"+8613800138000\",,\"24/01/01,12:00:00+32\"\r\nfirst\r\n\r\nthird\r\n" +
+			"+CMGL: 2,\"REC UNREAD\",\"+8613800138000\",,\"24/01/01,12:01:00+32\"\r\nsingle\r\n\r\nOK\r\n")
+		return nil
+	})
+	s.HandleFunc(`AT\+CMGR=1`, func(cmd string, match []string) []string {
+		s.Inject("\r\n+CMGR: \"REC READ\",\"+8613800138000\",,\"24/01/01,12:00:00+32\"\r\nfirst\r\n\r\nthird\r\n\r\nOK\r\n")
+		return nil
+	})
+
+	if err := d.SetSMSMode(1); err != nil {
+		t.Fatalf("SetSMSMode: %v", err)
+	}
+
+	list, err := d.ListSMSText(at.SMSStatusAll)
+	if err != nil {
+		t.Fatalf("ListSMSText: %v", err)
+	}
+	if len(list) != 2 || list[1].Text != "first\n\nthird" || list[0].Text != "single" {
+		t.Fatalf("ListSMSText = %+v", list)
+	}
+
+	msg, err := d.ReadSMSText(1)
+	if err != nil {
+		t.Fatalf("ReadSMSText: %v", err)
+	}
+	if msg.Text != "first\n\nthird" {
+		t.Fatalf("ReadSMSText = %+v", msg)
+	}
+}
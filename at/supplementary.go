@@ -0,0 +1,327 @@
+package at
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ServiceClass 补充业务的业务类别（<class>），可按位组合，0 表示模块默认（通常为 7）
+type ServiceClass int
+
+const (
+	ClassVoice     ServiceClass = 1   // 语音
+	ClassData      ServiceClass = 2   // 数据
+	ClassFax       ServiceClass = 4   // 传真
+	ClassSMS       ServiceClass = 8   // 短信
+	ClassDataSync  ServiceClass = 16  // 同步数据电路
+	ClassDataAsync ServiceClass = 32  // 异步数据电路
+	ClassPacket    ServiceClass = 64  // 专用分组接入
+	ClassPAD       ServiceClass = 128 // 专用 PAD 接入
+	ClassDefault   ServiceClass = 7   // 语音、数据和传真
+	ClassAll       ServiceClass = 255 // 全部类别
+)
+
+func (c ServiceClass) String() string {
+	names := []string{"voice", "data", "fax", "sms", "sync", "async", "packet", "pad"}
+	parts := []string{}
+	for i, name := range names {
+		if c&(1<<i) != 0 {
+			parts = append(parts, name)
+		}
+	}
+	if len(parts) == 0 || c>>len(names) != 0 {
+		return "unknown(" + strconv.Itoa(int(c)) + ")"
+	}
+	return strings.Join(parts, "|")
+}
+
+// ClassStatus 某一业务类别的启用状态
+type ClassStatus struct {
+	Class   ServiceClass `json:"class"`   // 业务类别
+	Enabled bool         `json:"enabled"` // 是否启用
+}
+
+// ForwardReason 呼叫转移条件（AT+CCFC 的 <reason>）
+type ForwardReason int
+
+const (
+	ForwardUnconditional  ForwardReason = 0 // 无条件转移
+	ForwardBusy           ForwardReason = 1 // 遇忙转移
+	ForwardNoReply        ForwardReason = 2 // 无应答转移
+	ForwardNotReachable   ForwardReason = 3 // 不可及转移
+	ForwardAll            ForwardReason = 4 // 全部转移（仅用于设置）
+	ForwardAllConditional ForwardReason = 5 // 全部条件转移（仅用于设置）
+)
+
+func (r ForwardReason) String() string {
+	switch r {
+	case ForwardUnconditional:
+		return "unconditional"
+	case ForwardBusy:
+		return "busy"
+	case ForwardNoReply:
+		return "no reply"
+	case ForwardNotReachable:
+		return "not reachable"
+	case ForwardAll:
+		return "all"
+	case ForwardAllConditional:
+		return "all conditional"
+	}
+	return "unknown(" + strconv.Itoa(int(r)) + ")"
+}
+
+// CallForward 呼叫转移设置
+type CallForward struct {
+	Class   ServiceClass `json:"class"`   // 业务类别
+	Enabled bool         `json:"enabled"` // 是否启用
+	Number  string       `json:"number"`  // 转移号码
+	Type    TypeOfNumber `json:"type"`    // 号码类型，为 0 时按号码格式自动选择
+	Time    int          `json:"time"`    // 无应答转移前的等待秒数（5-30），为 0 时使用网络默认值
+}
+
+// CLIRMode 本机号码显示限制的设置（AT+CLIR 的 <n>）
+type CLIRMode int
+
+const (
+	CLIRDefault    CLIRMode = 0 // 按签约设置
+	CLIRInvocation CLIRMode = 1 // 隐藏本机号码
+	CLIRSuppress   CLIRMode = 2 // 显示本机号码
+)
+
+func (c CLIRMode) String() string {
+	switch c {
+	case CLIRDefault:
+		return "default"
+	case CLIRInvocation:
+		return "invocation"
+	case CLIRSuppress:
+		return "suppression"
+	}
+	return "unknown(" + strconv.Itoa(int(c)) + ")"
+}
+
+// CLIRStatus 网络侧的号码显示限制签约状态（AT+CLIR 的 <m>）
+type CLIRStatus int
+
+const (
+	CLIRNotProvisioned      CLIRStatus = 0 // 未开通
+	CLIRPermanent           CLIRStatus = 1 // 永久隐藏
+	CLIRUnknown             CLIRStatus = 2 // 未知（如无网络）
+	CLIRTemporaryRestricted CLIRStatus = 3 // 临时模式，默认隐藏
+	CLIRTemporaryAllowed    CLIRStatus = 4 // 临时模式，默认显示
+)
+
+func (c CLIRStatus) String() string {
+	switch c {
+	case CLIRNotProvisioned:
+		return "not provisioned"
+	case CLIRPermanent:
+		return "permanent"
+	case CLIRUnknown:
+		return "unknown"
+	case CLIRTemporaryRestricted:
+		return "temporary restricted"
+	case CLIRTemporaryAllowed:
+		return "temporary allowed"
+	}
+	return "unknown(" + strconv.Itoa(int(c)) + ")"
+}
+
+// CLIRSetting 号码显示限制的本地设置和签约状态
+type CLIRSetting struct {
+	Mode   CLIRMode   `json:"mode"`   // 本地设置
+	Status CLIRStatus `json:"status"` // 网络签约状态
+}
+
+// GetCallForward 查询指定条件和业务类别的呼叫转移设置，class 为 0 时查询全部类别
+func (m *Device) GetCallForward(reason ForwardReason, class ServiceClass) ([]CallForward, error) {
+	return m.GetCallForwardContext(context.Background(), reason, class)
+}
+
+// GetCallForwardContext 查询呼叫转移设置
+func (m *Device) GetCallForwardContext(ctx context.Context, reason ForwardReason, class ServiceClass) ([]CallForward, error) {
+	cmd := fmt.Sprintf("%s=%d,2", m.commands.CallForward, reason)
+	if class > 0 {
+		cmd += fmt.Sprintf(",,,%d", class)
+	}
+	resp, err := m.SendCommandResponseContext(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	charset := m.currentCharset()
+	list := []CallForward{}
+	for _, param := range resp.All("+CCFC") {
+		// 格式: +CCFC: 1,1,"+8613800138000",145,,,20
+		status, ok := param.Int(0)
+		if !ok {
+			continue
+		}
+		cls, _ := param.Int(1)
+		typ, _ := param.Int(3)
+		seconds, _ := param.Int(6)
+		list = append(list, CallForward{
+			Class:   ServiceClass(cls),
+			Enabled: status == 1,
+			Number:  decodeNumber(charset, param.String(2)),
+			Type:    TypeOfNumber(typ),
+			Time:    seconds,
+		})
+	}
+	return list, nil
+}
+
+// RegisterCallForward 登记并启用呼叫转移号码，fwd.Enabled 被忽略
+func (m *Device) RegisterCallForward(reason ForwardReason, fwd CallForward) error {
+	return m.RegisterCallForwardContext(context.Background(), reason, fwd)
+}
+
+// RegisterCallForwardContext 登记并启用呼叫转移号码
+func (m *Device) RegisterCallForwardContext(ctx context.Context, reason ForwardReason, fwd CallForward) error {
+	typ := fwd.Type
+	if typ == 0 {
+		typ = numberType(fwd.Number)
+	}
+	number, err := encodeQuoted(m.currentCharset(), fwd.Number)
+	if err != nil {
+		return err
+	}
+
+	cmd := fmt.Sprintf(`%s=%d,3,%s,%d`, m.commands.CallForward, reason, number, typ)
+	if fwd.Class > 0 || fwd.Time > 0 {
+		cmd += ","
+		if fwd.Class > 0 {
+			cmd += strconv.Itoa(int(fwd.Class))
+		}
+	}
+	if fwd.Time > 0 {
+		cmd += fmt.Sprintf(",,,%d", fwd.Time)
+	}
+	return m.SendCommandExpectContext(ctx, cmd, "OK")
+}
+
+// EnableCallForward 启用已登记的呼叫转移
+func (m *Device) EnableCallForward(reason ForwardReason, class ServiceClass) error {
+	return m.EnableCallForwardContext(context.Background(), reason, class)
+}
+
+// EnableCallForwardContext 启用已登记的呼叫转移
+func (m *Device) EnableCallForwardContext(ctx context.Context, reason ForwardReason, class ServiceClass) error {
+	return m.setCallForward(ctx, reason, 1, class)
+}
+
+// DisableCallForward 停用呼叫转移，登记的号码保留
+func (m *Device) DisableCallForward(reason ForwardReason, class ServiceClass) error {
+	return m.DisableCallForwardContext(context.Background(), reason, class)
+}
+
+// DisableCallForwardContext 停用呼叫转移
+func (m *Device) DisableCallForwardContext(ctx context.Context, reason ForwardReason, class ServiceClass) error {
+	return m.setCallForward(ctx, reason, 0, class)
+}
+
+// EraseCallForward 清除登记的呼叫转移号码
+func (m *Device) EraseCallForward(reason ForwardReason, class ServiceClass) error {
+	return m.EraseCallForwardContext(context.Background(), reason, class)
+}
+
+// EraseCallForwardContext 清除登记的呼叫转移号码
+func (m *Device) EraseCallForwardContext(ctx context.Context, reason ForwardReason, class ServiceClass) error {
+	return m.setCallForward(ctx, reason, 4, class)
+}
+
+// setCallForward 发送不带号码的呼叫转移操作
+func (m *Device) setCallForward(ctx context.Context, reason ForwardReason, mode int, class ServiceClass) error {
+	cmd := fmt.Sprintf("%s=%d,%d", m.commands.CallForward, reason, mode)
+	if class > 0 {
+		cmd += fmt.Sprintf(",,,%d", class)
+	}
+	return m.SendCommandExpectContext(ctx, cmd, "OK")
+}
+
+// GetCallWaiting 查询各业务类别的呼叫等待状态，class 为 0 时查询全部类别
+//
+// 查询同时开启 +CCWA 等待来电通知。
+func (m *Device) GetCallWaiting(class ServiceClass) ([]ClassStatus, error) {
+	return m.GetCallWaitingContext(context.Background(), class)
+}
+
+// GetCallWaitingContext 查询各业务类别的呼叫等待状态
+func (m *Device) GetCallWaitingContext(ctx context.Context, class ServiceClass) ([]ClassStatus, error) {
+	cmd := m.commands.CallWaiting + "=1,2"
+	if class > 0 {
+		cmd += "," + strconv.Itoa(int(class))
+	}
+	resp, err := m.SendCommandResponseContext(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []ClassStatus{}
+	for _, param := range resp.All("+CCWA") {
+		// 格式: +CCWA: 1,1
+		status, ok := param.Int(0)
+		if !ok {
+			continue
+		}
+		cls, _ := param.Int(1)
+		list = append(list, ClassStatus{Class: ServiceClass(cls), Enabled: status == 1})
+	}
+	return list, nil
+}
+
+// SetCallWaiting 启用或停用呼叫等待，class 为 0 时作用于模块默认类别
+//
+// 同时开启 +CCWA 等待来电通知。
+func (m *Device) SetCallWaiting(enable bool, class ServiceClass) error {
+	return m.SetCallWaitingContext(context.Background(), enable, class)
+}
+
+// SetCallWaitingContext 启用或停用呼叫等待
+func (m *Device) SetCallWaitingContext(ctx context.Context, enable bool, class ServiceClass) error {
+	mode := 0
+	if enable {
+		mode = 1
+	}
+	cmd := fmt.Sprintf("%s=1,%d", m.commands.CallWaiting, mode)
+	if class > 0 {
+		cmd += "," + strconv.Itoa(int(class))
+	}
+	return m.SendCommandExpectContext(ctx, cmd, "OK")
+}
+
+// GetCLIR 查询本机号码显示限制的设置和签约状态
+func (m *Device) GetCLIR() (CLIRSetting, error) {
+	return m.GetCLIRContext(context.Background())
+}
+
+// GetCLIRContext 查询本机号码显示限制的设置和签约状态
+func (m *Device) GetCLIRContext(ctx context.Context) (CLIRSetting, error) {
+	resp, err := m.SendCommandResponseContext(ctx, m.commands.CLIR+"?")
+	if err != nil {
+		return CLIRSetting{}, err
+	}
+
+	// 格式: +CLIR: 0,4
+	if param, ok := resp.Params("+CLIR"); ok && len(param) >= 2 {
+		mode, _ := param.Int(0)
+		status, _ := param.Int(1)
+		return CLIRSetting{Mode: CLIRMode(mode), Status: CLIRStatus(status)}, nil
+	}
+
+	return CLIRSetting{}, fmt.Errorf("failed to parse CLIR status")
+}
+
+// SetCLIR 设置呼出时是否隐藏本机号码
+func (m *Device) SetCLIR(mode CLIRMode) error {
+	return m.SetCLIRContext(context.Background(), mode)
+}
+
+// SetCLIRContext 设置呼出时是否隐藏本机号码
+func (m *Device) SetCLIRContext(ctx context.Context, mode CLIRMode) error {
+	cmd := fmt.Sprintf("%s=%d", m.commands.CLIR, mode)
+	return m.SendCommandExpectContext(ctx, cmd, "OK")
+}
@@ -0,0 +1,77 @@
+package at_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rehiy/modem/at"
+)
+
+func TestGetCallForward(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`AT\+CCFC=2,2`,
+		"+CCFC: 0,1",
+		`+CCFC: 1,4,"+8613800138000",145,,,20`,
+		`+CCFC: 1,1,"13800138000",129`,
+		"OK")
+
+	list, err := d.GetCallForward(at.ForwardNoReply, 0)
+	if err != nil {
+		t.Fatalf("GetCallForward: %v", err)
+	}
+	want := []at.CallForward{
+		{Class: at.ClassVoice},
+		{Class: at.ClassFax, Enabled: true, Number: "+8613800138000", Type: 145, Time: 20},
+		{Class: at.ClassVoice, Enabled: true, Number: "13800138000", Type: 129},
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Fatalf("GetCallForward = %+v", list)
+	}
+}
+
+func TestRegisterCallForward(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`AT\+CCFC=.*`, "OK")
+
+	// <class> 为第 5 个参数，<time> 为第 8 个参数
+	tests := []struct {
+		fwd  at.CallForward
+		want string
+	}{
+		{at.CallForward{Number: "+8613800138000"}, `AT+CCFC=2,3,"+8613800138000",145`},
+		{at.CallForward{Number: "13800138000", Class: at.ClassVoice}, `AT+CCFC=2,3,"13800138000",129,1`},
+		{at.CallForward{Number: "13800138000", Time: 20}, `AT+CCFC=2,3,"13800138000",129,,,,20`},
+		{at.CallForward{Number: "13800138000", Class: at.ClassVoice, Time: 20}, `AT+CCFC=2,3,"13800138000",129,1,,,20`},
+	}
+	for _, tt := range tests {
+		if err := d.RegisterCallForward(at.ForwardNoReply, tt.fwd); err != nil {
+			t.Fatalf("RegisterCallForward(%+v): %v", tt.fwd, err)
+		}
+		history := s.History()
+		if got := history[len(history)-1]; got != tt.want {
+			t.Fatalf("RegisterCallForward(%+v) sent %q, want %q", tt.fwd, got, tt.want)
+		}
+	}
+}
+
+func TestGetCLIR(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`AT\+CLIR\?`, "+CLIR: 1,4", "OK")
+
+	setting, err := d.GetCLIR()
+	if err != nil {
+		t.Fatalf("GetCLIR: %v", err)
+	}
+	if setting.Mode != at.CLIRInvocation || setting.Status != at.CLIRTemporaryAllowed {
+		t.Fatalf("GetCLIR = %+v", setting)
+	}
+	if setting.Mode.String() != "invocation" || setting.Status.String() != "temporary allowed" {
+		t.Fatalf("GetCLIR = %s, %s", setting.Mode, setting.Status)
+	}
+	if name := at.CLIRMode(5).String(); name != "unknown(5)" {
+		t.Fatalf("CLIRMode(5) = %q", name)
+	}
+	if name := at.CLIRStatus(9).String(); name != "unknown(9)" {
+		t.Fatalf("CLIRStatus(9) = %q", name)
+	}
+}
@@ -0,0 +1,240 @@
+package at
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rehiy/modem/sms/gsm7"
+)
+
+// USSDStatus USSD 响应状态（+CUSD 的 <m>）
+type USSDStatus int
+
+const (
+	USSDDone           USSDStatus = 0 // 无需进一步操作
+	USSDActionRequired USSDStatus = 1 // 需要用户继续输入
+	USSDTerminated     USSDStatus = 2 // 会话被网络终止
+	USSDOtherClient    USSDStatus = 3 // 其他本地客户端已响应
+	USSDNotSupported   USSDStatus = 4 // 操作不支持
+	USSDTimeout        USSDStatus = 5 // 网络超时
+)
+
+func (s USSDStatus) String() string {
+	switch s {
+	case USSDDone:
+		return "done"
+	case USSDActionRequired:
+		return "action required"
+	case USSDTerminated:
+		return "terminated"
+	case USSDOtherClient:
+		return "other client"
+	case USSDNotSupported:
+		return "not supported"
+	case USSDTimeout:
+		return "network timeout"
+	}
+	return "unknown(" + strconv.Itoa(int(s)) + ")"
+}
+
+// USSDReply USSD 网络响应
+type USSDReply struct {
+	Status  USSDStatus `json:"status"`  // 响应状态，USSDActionRequired 时可继续调用 USSD 发送输入
+	Text    string     `json:"text"`    // 解码后的消息内容
+	Message string     `json:"message"` // 原始消息内容
+	DCS     int        `json:"dcs"`     // 数据编码方案（3GPP TS 23.038 第 5 章），未上报时为 -1
+}
+
+// USSD 发送 USSD 请求（如 "*100#"）并等待网络响应
+//
+// 响应状态为 USSDActionRequired 时会话保持，可再次调用 USSD 发送菜单选项，或调用 CancelUSSD 结束会话。
+func (m *Device) USSD(code string) (USSDReply, error) {
+	return m.USSDContext(context.Background(), code)
+}
+
+// USSDContext 发送 USSD 请求并等待网络响应，支持通过 ctx 取消
+func (m *Device) USSDContext(ctx context.Context, code string) (USSDReply, error) {
+	str, err := m.encodeUSSD(code)
+	if err != nil {
+		return USSDReply{}, err
+	}
+
+	// 先订阅再发送，网络响应可能在命令的最终响应之后立即到达
+	events, cancel := m.Subscribe(m.notifications.USSD)
+	defer cancel()
+
+	cmd := fmt.Sprintf(`%s=1,%s,15`, m.commands.USSD, str)
+	resp, err := m.SendCommandResponseContext(ctx, cmd)
+	if err != nil {
+		return USSDReply{}, err
+	}
+
+	// 部分模块在最终响应之前返回 +CUSD
+	if param, ok := resp.Params(m.notifications.USSD); ok {
+		return m.ussdReply(param.Map())
+	}
+
+	timeout := m.commandTimeout(m.commands.USSD)
+	if _, ok := ctx.Deadline(); ok {
+		timeout = 0
+	}
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case event, ok := <-events:
+		if !ok {
+			return USSDReply{}, ErrClosed
+		}
+		return m.ussdReply(event.Param)
+	case <-expired:
+		return USSDReply{}, ErrTimeout
+	case <-ctx.Done():
+		return USSDReply{}, ctx.Err()
+	}
+}
+
+// CancelUSSD 结束当前 USSD 会话
+func (m *Device) CancelUSSD() error {
+	return m.CancelUSSDContext(context.Background())
+}
+
+// CancelUSSDContext 结束当前 USSD 会话
+func (m *Device) CancelUSSDContext(ctx context.Context) error {
+	return m.SendCommandExpectContext(ctx, m.commands.USSD+"=2", "OK")
+}
+
+// ussdReply 解析 +CUSD 参数，网络不支持或超时时返回错误
+func (m *Device) ussdReply(param map[int]string) (USSDReply, error) {
+	// 格式: +CUSD: 1,"Balance: 10.00",15
+	reply := newUSSDReply(m.currentCharset(), param)
+	switch reply.Status {
+	case USSDNotSupported, USSDTimeout:
+		return reply, fmt.Errorf("ussd %s", reply.Status)
+	}
+	return reply, nil
+}
+
+// newUSSDReply 根据 +CUSD 参数构造响应
+func newUSSDReply(charset string, param map[int]string) USSDReply {
+	reply := USSDReply{
+		Status:  USSDStatus(parseInt(param[0])),
+		Message: param[1],
+		DCS:     -1,
+	}
+	if v, ok := param[2]; ok && v != "" {
+		reply.DCS = parseInt(v)
+	}
+	reply.Text = decodeUSSD(charset, reply.DCS, reply.Message)
+	return reply
+}
+
+// encodeUSSD 编码 USSD 请求并放入双引号，Config.USSDPacked 为 true 时以 GSM7 打包的十六进制发送
+func (m *Device) encodeUSSD(code string) (string, error) {
+	if !m.ussdPacked {
+		return encodeQuoted(m.currentCharset(), code)
+	}
+
+	septets, err := gsm7.Encode([]byte(code))
+	if err != nil {
+		return "", err
+	}
+	return quoteString(strings.ToUpper(hex.EncodeToString(gsm7.Pack7BitUSSD(septets, 0))))
+}
+
+// decodeUSSD 按数据编码方案解码 USSD 消息
+//
+// UCS2 消息以十六进制传输；GSM7 消息可能是模块按 TE 字符集转换后的文本，
+// 也可能是未经转换的打包十六进制（如华为模块），后者解码结果均为可打印字符时采用。
+func decodeUSSD(charset string, dcs int, msg string) string {
+	switch ussdAlphabet(dcs) {
+	case alphabetUCS2:
+		if text, err := decodeUCS2Hex(msg); err == nil {
+			return text
+		}
+
+	case alphabet8Bit:
+		if b, err := hex.DecodeString(msg); err == nil {
+			return string(b)
+		}
+
+	case alphabetGSM7:
+		if !strings.EqualFold(charset, CharsetUCS2) && !strings.EqualFold(charset, CharsetHex) {
+			if text, ok := unpackUSSD(msg); ok {
+				return text
+			}
+		}
+	}
+	return decodeTextField(charset, msg)
+}
+
+// unpackUSSD 尝试将十六进制字符串按 GSM7 打包格式解码
+func unpackUSSD(msg string) (string, bool) {
+	if len(msg) < 2 || !isHex(msg) {
+		return "", false
+	}
+	packed, err := hex.DecodeString(msg)
+	if err != nil {
+		return "", false
+	}
+	text, err := gsm7.Decode(gsm7.Unpack7BitUSSD(packed, 0))
+	if err != nil {
+		return "", false
+	}
+	for _, r := range string(text) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return "", false
+		}
+	}
+	return string(text), true
+}
+
+// USSD 消息字母表
+const (
+	alphabetGSM7 = iota
+	alphabet8Bit
+	alphabetUCS2
+)
+
+// ussdAlphabet 根据小区广播数据编码方案（3GPP TS 23.038 第 5 章）判断字母表，未知时按 GSM7 处理
+func ussdAlphabet(dcs int) int {
+	if dcs < 0 {
+		return alphabetGSM7
+	}
+
+	switch {
+	case dcs == 0x11:
+		// GSM7 语言标识后接 UCS2
+		return alphabetUCS2
+	case dcs&0xC0 == 0x40:
+		// 通用数据编码，第 2-3 位为字母表
+		switch dcs & 0x0C {
+		case 0x04:
+			return alphabet8Bit
+		case 0x08:
+			return alphabetUCS2
+		}
+	case dcs&0xF0 == 0x90:
+		// 消息类别
+		switch dcs & 0x0C {
+		case 0x04:
+			return alphabet8Bit
+		case 0x08:
+			return alphabetUCS2
+		}
+	case dcs&0xF0 == 0xF0:
+		if dcs&0x04 != 0 {
+			return alphabet8Bit
+		}
+	}
+	return alphabetGSM7
+}
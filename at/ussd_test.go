@@ -0,0 +1,39 @@
+package at_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rehiy/modem/at"
+)
+
+func TestUSSDReplyAfterOK(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	// +CUSD 与 OK 在同一次读取中到达
+	s.Handle(`AT\+CUSD=1,.*`, "OK", `+CUSD: 0,"Balance 10",15`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	reply, err := d.USSDContext(ctx, "*100#")
+	if err != nil {
+		t.Fatalf("USSD: %v", err)
+	}
+	if reply.Status != at.USSDDone || reply.Text != "Balance 10" {
+		t.Fatalf("USSD = %+v", reply)
+	}
+}
+
+func TestUSSDReplyBeforeOK(t *testing.T) {
+	d, s := newSimDevice(t, nil)
+	s.Handle(`AT\+CUSD=1,.*`, `+CUSD: 1,"1. Balance",15`, "OK")
+
+	reply, err := d.USSD("*100#")
+	if err != nil {
+		t.Fatalf("USSD: %v", err)
+	}
+	if reply.Status != at.USSDActionRequired || reply.Text != "1. Balance" {
+		t.Fatalf("USSD = %+v", reply)
+	}
+}
@@ -0,0 +1,231 @@
+package cmux
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrChannelClosed 通道已断开
+var ErrChannelClosed = errors.New("cmux: channel closed")
+
+// timeoutError 读取超时，Timeout() 为 true
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "cmux: read timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// Channel 逻辑通道，实现 at.Port
+//
+// 收到的数据先写入通道自己的缓冲区，不会因某个通道无人读取而阻塞其他通道。
+// 缓冲超过一半时通过 MSC 的 FC 位要求模块暂停本通道，读到四分之一以下时恢复；
+// 模块不理会流控时，写满后新数据被丢弃并计入 Dropped。
+type Channel struct {
+	mux     *Mux
+	dlci    int
+	ready   chan struct{} // 缓冲区有新数据时发送信号
+	closed  chan struct{} // 通道断开时关闭
+	once    sync.Once
+	err     error // 通道断开的原因
+	signals byte  // 模块最近通过 MSC 通知的 V.24 信号
+
+	rmu     sync.Mutex // 保护以下接收状态
+	buf     []byte     // 尚未读取的数据
+	stopped bool       // 已要求模块暂停发送
+	dropped int        // 缓冲区满时丢弃的字节数
+}
+
+func newChannel(m *Mux, dlci int) *Channel {
+	return &Channel{
+		mux:    m,
+		dlci:   dlci,
+		ready:  make(chan struct{}, 1),
+		closed: make(chan struct{}),
+	}
+}
+
+// DLCI 返回通道编号
+func (c *Channel) DLCI() int {
+	return c.dlci
+}
+
+// Read 读取数据，超时返回 Timeout() 为 true 的错误，通道断开后返回断开原因
+func (c *Channel) Read(buf []byte) (int, error) {
+	var expired <-chan time.Time
+	if c.mux.config.ReadTimeout > 0 {
+		timer := time.NewTimer(c.mux.config.ReadTimeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		if n := c.take(buf); n > 0 {
+			return n, nil
+		}
+
+		select {
+		case <-c.ready:
+		case <-c.closed:
+			// 断开前收到的数据仍可读出
+			if n := c.take(buf); n > 0 {
+				return n, nil
+			}
+			return 0, c.err
+		case <-expired:
+			return 0, timeoutError{}
+		}
+	}
+}
+
+// Dropped 返回因接收缓冲区已满而丢弃的字节数
+func (c *Channel) Dropped() int {
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+	return c.dropped
+}
+
+// take 从缓冲区取出数据，低于恢复水位时解除流控
+func (c *Channel) take(buf []byte) int {
+	c.rmu.Lock()
+	n := copy(buf, c.buf)
+	c.buf = c.buf[n:]
+	resume := c.stopped && len(c.buf) <= c.mux.config.BufferSize/4
+	if resume {
+		c.stopped = false
+	}
+	c.rmu.Unlock()
+
+	if resume {
+		c.sendSignals(false)
+	}
+	return n
+}
+
+// Write 按帧长度分段写入数据，模块要求流控时等待
+func (c *Channel) Write(data []byte) (int, error) {
+	written := 0
+	for written < len(data) {
+		if err := c.waitFlow(); err != nil {
+			return written, err
+		}
+
+		end := min(written+c.mux.config.FrameSize, len(data))
+		if err := c.mux.writeFrame(c.dlci, ctrlUIH, true, data[written:end]); err != nil {
+			return written, err
+		}
+		written = end
+	}
+	return written, nil
+}
+
+// Flush 丢弃尚未读取的数据
+func (c *Channel) Flush() error {
+	c.rmu.Lock()
+	c.buf = nil
+	resume := c.stopped
+	c.stopped = false
+	c.rmu.Unlock()
+
+	if resume {
+		return c.sendSignals(false)
+	}
+	return nil
+}
+
+// Close 断开通道，复用器和其他通道不受影响
+func (c *Channel) Close() error {
+	select {
+	case <-c.closed:
+		return nil
+	default:
+	}
+
+	err := c.mux.disconnect(c.dlci)
+	c.shutdown(ErrChannelClosed)
+	c.mux.remove(c)
+	return err
+}
+
+// deliver 投递收到的数据，不阻塞读取循环
+func (c *Channel) deliver(data []byte) {
+	size := c.mux.config.BufferSize
+
+	c.rmu.Lock()
+	if room := size - len(c.buf); len(data) > room {
+		c.dropped += len(data) - max(room, 0)
+		data = data[:max(room, 0)]
+	}
+	c.buf = append(c.buf, data...)
+	stop := !c.stopped && len(c.buf) > size/2
+	if stop {
+		c.stopped = true
+	}
+	c.rmu.Unlock()
+
+	select {
+	case c.ready <- struct{}{}:
+	default:
+	}
+	if stop {
+		c.sendSignals(true)
+	}
+}
+
+// sendSignals 通过 MSC 通知本端的 V.24 信号，fc 为 true 时要求模块暂停本通道的发送
+func (c *Channel) sendSignals(fc bool) error {
+	signals := byte(signalRTC | signalRTR | signalDV | 0x01)
+	if fc {
+		signals |= signalFC
+	}
+	return c.mux.sendControl(msgMSC, true, []byte{byte(c.dlci<<2) | 0x03, signals})
+}
+
+// shutdown 标记通道断开
+func (c *Channel) shutdown(err error) {
+	c.once.Do(func() {
+		c.err = err
+		close(c.closed)
+	})
+}
+
+// setSignals 记录模块通知的 V.24 信号，FC 位控制本通道的发送
+func (c *Channel) setSignals(signals byte) {
+	c.mux.mu.Lock()
+	defer c.mux.mu.Unlock()
+	c.signals = signals
+	c.mux.notify()
+}
+
+// DataValid 返回模块最近通知的 DV（数据有效）信号，可用于判断数据连接是否仍然存在
+func (c *Channel) DataValid() bool {
+	c.mux.mu.Lock()
+	defer c.mux.mu.Unlock()
+	return c.signals&signalDV != 0
+}
+
+// Ring 返回模块最近通知的 IC（来电指示）信号
+func (c *Channel) Ring() bool {
+	c.mux.mu.Lock()
+	defer c.mux.mu.Unlock()
+	return c.signals&signalIC != 0
+}
+
+// waitFlow 等待全局和本通道的流控解除
+func (c *Channel) waitFlow() error {
+	for {
+		c.mux.mu.Lock()
+		stopped := c.mux.fcoff || c.signals&signalFC != 0
+		changed := c.mux.changed
+		c.mux.mu.Unlock()
+		if !stopped {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-c.closed:
+			return c.err
+		}
+	}
+}
@@ -0,0 +1,480 @@
+// Package cmux 实现 3GPP TS 27.010 串口多路复用
+//
+// 在一个物理串口上协商 AT+CMUX 后建立多个逻辑通道（DLCI），每个通道都实现 at.Port，
+// 可分别交给 at.Device 处理命令和通知，或承载 PPP、GNSS NMEA 等数据流。
+package cmux
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rehiy/modem/at"
+)
+
+var (
+	// ErrClosed 复用器已关闭
+	ErrClosed = errors.New("cmux: closed")
+	// ErrRejected 模块拒绝建立通道（DM 响应）
+	ErrRejected = errors.New("cmux: channel rejected")
+	// ErrNoResponse 模块在重试次数内未响应
+	ErrNoResponse = errors.New("cmux: no response")
+)
+
+// Mode 帧模式
+type Mode int
+
+const (
+	Basic    Mode = 0 // 基本模式，帧以 0xF9 分隔并带长度字段
+	Advanced Mode = 1 // 高级模式，帧以 0x7E 分隔并做透明转义
+)
+
+// 控制通道消息类型（不含 C/R 和 EA 位）
+const (
+	msgPN    = 0x80 // 参数协商
+	msgPSC   = 0x40 // 省电控制
+	msgCLD   = 0xC0 // 关闭复用
+	msgTest  = 0x20 // 测试
+	msgFCon  = 0xA0 // 全局流控开
+	msgFCoff = 0x60 // 全局流控关
+	msgMSC   = 0xE0 // 调制解调器状态
+	msgNSC   = 0x10 // 不支持的命令
+)
+
+// MSC V.24 信号位
+const (
+	signalFC  = 0x02 // 流控，置位时暂停发送
+	signalRTC = 0x04 // 准备好通信
+	signalRTR = 0x08 // 准备好接收
+	signalIC  = 0x40 // 来电指示
+	signalDV  = 0x80 // 数据有效
+)
+
+// Config 复用器配置
+type Config struct {
+	Mode        Mode          // 帧模式，默认基本模式
+	FrameSize   int           // 信息字段最大长度 N1，默认 127
+	Command     string        // 进入复用模式的命令，默认 AT+CMUX=<mode>,0,,<N1>，不指定端口速率
+	NoNegotiate bool          // 模块已处于复用模式时跳过 AT+CMUX
+	Timeout     time.Duration // 等待响应的时间 T1，默认 1 秒
+	Retries     int           // 重试次数 N2，默认 3
+	ReadTimeout time.Duration // 通道 Read 无数据时的超时，为 0 时一直等待
+	BufferSize  int           // 每个通道的接收缓冲字节数，默认 64 KiB
+}
+
+// Mux 多路复用器
+type Mux struct {
+	port     at.Port
+	config   Config
+	wmu      sync.Mutex // 保护底层串口写入
+	mu       sync.Mutex // 保护以下状态
+	channels map[int]*Channel
+	waiters  map[int]chan frame // 按 DLCI 等待 UA/DM 的请求，waitCLD 等待关闭复用响应
+	fcoff    bool               // 模块要求暂停全部发送
+	changed  chan struct{}      // 流控状态变化时关闭并替换
+	err      error              // 复用器失效的原因
+	done     chan struct{}      // 复用器失效或关闭时关闭
+}
+
+// waitCLD 等待关闭复用响应的键
+const waitCLD = -1
+
+// Open 在串口上进入复用模式并建立控制通道（DLCI 0）
+//
+// 串口应设置读取超时，以免协商 AT+CMUX 时无响应而一直阻塞。
+func Open(port at.Port, config *Config) (*Mux, error) {
+	if config == nil {
+		config = &Config{}
+	}
+	cfg := *config
+	if cfg.FrameSize == 0 {
+		cfg.FrameSize = 127
+	}
+	if cfg.Command == "" {
+		// 端口速率留空，保持当前波特率
+		cfg.Command = fmt.Sprintf("AT+CMUX=%d,0,,%d", cfg.Mode, cfg.FrameSize)
+	}
+	if cfg.BufferSize == 0 {
+		cfg.BufferSize = 64 * 1024
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = time.Second
+	}
+	if cfg.Retries == 0 {
+		cfg.Retries = 3
+	}
+
+	m := &Mux{
+		port:     port,
+		config:   cfg,
+		channels: map[int]*Channel{},
+		waiters:  map[int]chan frame{},
+		changed:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	if !cfg.NoNegotiate {
+		if err := m.negotiate(); err != nil {
+			return nil, err
+		}
+	}
+
+	go m.readLoop()
+
+	if err := m.connect(0); err != nil {
+		m.fail(err)
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// negotiate 发送 AT+CMUX 并等待 OK
+func (m *Mux) negotiate() error {
+	if _, err := m.port.Write([]byte(m.config.Command + "\r")); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(m.config.Timeout * time.Duration(m.config.Retries))
+	buf := make([]byte, 256)
+	response := ""
+	for time.Now().Before(deadline) {
+		n, err := m.port.Read(buf)
+		response += string(buf[:n])
+		for _, line := range strings.Split(response, "\n") {
+			switch line = strings.TrimSpace(line); {
+			case line == "OK":
+				return nil
+			case strings.Contains(line, "ERROR"):
+				return fmt.Errorf("cmux: %s: %s", m.config.Command, line)
+			}
+		}
+		if err != nil && !isTimeout(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("cmux: %s: %w", m.config.Command, ErrNoResponse)
+}
+
+// Channel 建立逻辑通道，dlci 取值 1 至 63
+func (m *Mux) Channel(dlci int) (*Channel, error) {
+	if dlci < 1 || dlci > 63 {
+		return nil, fmt.Errorf("cmux: invalid dlci %d", dlci)
+	}
+
+	m.mu.Lock()
+	if m.channels[dlci] != nil {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("cmux: dlci %d already open", dlci)
+	}
+	ch := newChannel(m, dlci)
+	m.channels[dlci] = ch
+	m.mu.Unlock()
+
+	if err := m.connect(dlci); err != nil {
+		m.remove(ch)
+		return nil, err
+	}
+
+	// 部分模块要求通道建立后收到 MSC 才开始收发数据
+	ch.sendSignals(false)
+
+	return ch, nil
+}
+
+// Close 断开全部通道，退出复用模式并关闭底层串口
+func (m *Mux) Close() error {
+	m.mu.Lock()
+	channels := make([]*Channel, 0, len(m.channels))
+	for _, ch := range m.channels {
+		channels = append(channels, ch)
+	}
+	m.mu.Unlock()
+
+	for _, ch := range channels {
+		ch.Close()
+	}
+
+	// 关闭复用后模块回到 AT 命令模式
+	select {
+	case <-m.done:
+	default:
+		if _, err := m.request(waitCLD, func() error {
+			return m.sendControl(msgCLD, true, nil)
+		}); err != nil {
+			m.disconnect(0)
+		}
+	}
+
+	m.fail(ErrClosed)
+	return m.port.Close()
+}
+
+// Err 返回复用器失效的原因，正常运行时为 nil
+func (m *Mux) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
+
+// Done 返回复用器失效或关闭时关闭的通道
+func (m *Mux) Done() <-chan struct{} {
+	return m.done
+}
+
+// connect 发送 SABM 并等待 UA
+func (m *Mux) connect(dlci int) error {
+	f, err := m.request(dlci, func() error {
+		return m.writeFrame(dlci, ctrlSABM|ctrlPF, true, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("cmux: connect dlci %d: %w", dlci, err)
+	}
+	if f.control == ctrlDM {
+		return fmt.Errorf("cmux: connect dlci %d: %w", dlci, ErrRejected)
+	}
+	return nil
+}
+
+// disconnect 发送 DISC 并等待 UA
+func (m *Mux) disconnect(dlci int) error {
+	_, err := m.request(dlci, func() error {
+		return m.writeFrame(dlci, ctrlDISC|ctrlPF, true, nil)
+	})
+	return err
+}
+
+// request 发送请求并等待响应，超时按配置重试
+func (m *Mux) request(key int, send func() error) (frame, error) {
+	wait := make(chan frame, 1)
+	m.mu.Lock()
+	m.waiters[key] = wait
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		delete(m.waiters, key)
+		m.mu.Unlock()
+	}()
+
+	for i := 0; i < m.config.Retries; i++ {
+		if err := send(); err != nil {
+			return frame{}, err
+		}
+
+		timer := time.NewTimer(m.config.Timeout)
+		select {
+		case f := <-wait:
+			timer.Stop()
+			return f, nil
+		case <-m.done:
+			timer.Stop()
+			return frame{}, m.Err()
+		case <-timer.C:
+		}
+	}
+	return frame{}, ErrNoResponse
+}
+
+// respond 将响应交给等待中的请求
+func (m *Mux) respond(key int, f frame) {
+	m.mu.Lock()
+	wait := m.waiters[key]
+	m.mu.Unlock()
+
+	if wait != nil {
+		select {
+		case wait <- f:
+		default:
+		}
+	}
+}
+
+// readLoop 读取底层串口并分发帧
+func (m *Mux) readLoop() {
+	parse := parseBasic
+	if m.config.Mode == Advanced {
+		parse = parseAdvanced
+	}
+
+	buf := make([]byte, 4096)
+	pending := []byte{}
+	for {
+		n, err := m.port.Read(buf)
+		if n > 0 {
+			var frames []frame
+			frames, pending = parse(append(pending, buf[:n]...))
+			for _, f := range frames {
+				m.dispatch(f)
+			}
+		}
+
+		if err != nil && !isTimeout(err) {
+			m.fail(err)
+			return
+		}
+		select {
+		case <-m.done:
+			return
+		default:
+		}
+	}
+}
+
+// dispatch 处理一帧
+func (m *Mux) dispatch(f frame) {
+	switch f.control {
+	case ctrlUA, ctrlDM:
+		m.respond(f.dlci, f)
+
+	case ctrlDISC:
+		m.writeFrame(f.dlci, ctrlUA|ctrlPF, false, nil)
+		if f.dlci == 0 {
+			m.fail(ErrClosed)
+			return
+		}
+		if ch := m.channel(f.dlci); ch != nil {
+			ch.shutdown(ErrChannelClosed)
+			m.remove(ch)
+		}
+
+	case ctrlSABM:
+		// 只由本端发起建立通道
+		m.writeFrame(f.dlci, ctrlDM|ctrlPF, false, nil)
+
+	case ctrlUIH, ctrlUI:
+		if f.dlci == 0 {
+			m.handleControl(f.info)
+			return
+		}
+		if ch := m.channel(f.dlci); ch != nil {
+			ch.deliver(f.info)
+		}
+	}
+}
+
+// handleControl 处理控制通道消息
+func (m *Mux) handleControl(info []byte) {
+	for len(info) >= 2 {
+		kind, command := info[0]&^0x03, info[0]&0x02 != 0
+
+		// 长度字段为 1 或 2 字节
+		length, header := int(info[1]>>1), 2
+		if info[1]&0x01 == 0 && len(info) >= 3 {
+			length |= int(info[2]) << 7
+			header = 3
+		}
+		if len(info) < header+length {
+			return
+		}
+		values := info[header : header+length]
+		info = info[header+length:]
+
+		if !command {
+			if kind == msgCLD {
+				m.respond(waitCLD, frame{})
+			}
+			continue
+		}
+
+		switch kind {
+		case msgMSC:
+			if len(values) >= 2 {
+				if ch := m.channel(int(values[0] >> 2)); ch != nil {
+					ch.setSignals(values[1])
+				}
+			}
+			m.sendControl(kind, false, values)
+
+		case msgFCon, msgFCoff:
+			m.setFlow(kind == msgFCoff)
+			m.sendControl(kind, false, values)
+
+		case msgTest, msgPSC, msgPN:
+			m.sendControl(kind, false, values)
+
+		case msgCLD:
+			m.sendControl(kind, false, nil)
+			m.fail(ErrClosed)
+
+		default:
+			m.sendControl(msgNSC, false, []byte{kind | 0x03})
+		}
+	}
+}
+
+// sendControl 在控制通道发送消息
+func (m *Mux) sendControl(kind byte, command bool, values []byte) error {
+	typ := kind | 0x01
+	if command {
+		typ |= 0x02
+	}
+	info := append([]byte{typ, byte(len(values)<<1) | 0x01}, values...)
+	return m.writeFrame(0, ctrlUIH, true, info)
+}
+
+// writeFrame 编码并写入一帧
+func (m *Mux) writeFrame(dlci int, control byte, cr bool, info []byte) error {
+	data := encode(m.config.Mode, dlci, control, cr, info)
+
+	m.wmu.Lock()
+	defer m.wmu.Unlock()
+	_, err := m.port.Write(data)
+	return err
+}
+
+// setFlow 设置全局流控
+func (m *Mux) setFlow(off bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fcoff = off
+	m.notify()
+}
+
+// notify 唤醒等待流控的写入，调用方需持有锁
+func (m *Mux) notify() {
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+// channel 返回已建立的通道
+func (m *Mux) channel(dlci int) *Channel {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.channels[dlci]
+}
+
+// remove 移除通道
+func (m *Mux) remove(ch *Channel) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.channels[ch.dlci] == ch {
+		delete(m.channels, ch.dlci)
+	}
+}
+
+// fail 标记复用器失效并关闭全部通道
+func (m *Mux) fail(err error) {
+	m.mu.Lock()
+	if m.err != nil {
+		m.mu.Unlock()
+		return
+	}
+	m.err = err
+	close(m.done)
+	channels := m.channels
+	m.channels = map[int]*Channel{}
+	m.mu.Unlock()
+
+	for _, ch := range channels {
+		ch.shutdown(err)
+	}
+}
+
+// isTimeout 判断是否为读取超时
+func isTimeout(err error) bool {
+	t, ok := err.(interface{ Timeout() bool })
+	return ok && t.Timeout()
+}
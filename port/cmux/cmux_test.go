@@ -0,0 +1,150 @@
+package cmux
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeModule 模拟处于复用模式的模块：应答 AT+CMUX、SABM、DISC 和 CLD，并记录收到的帧
+type fakeModule struct {
+	rx       chan []byte // 发往复用器的数据
+	closed   chan struct{}
+	once     sync.Once
+	mu       sync.Mutex
+	commands []string
+	frames   []frame
+	pending  []byte
+}
+
+func newFakeModule() *fakeModule {
+	return &fakeModule{rx: make(chan []byte, 1024), closed: make(chan struct{})}
+}
+
+func (p *fakeModule) Read(buf []byte) (int, error) {
+	select {
+	case data := <-p.rx:
+		return copy(buf, data), nil
+	case <-p.closed:
+		return 0, io.ErrClosedPipe
+	case <-time.After(10 * time.Millisecond):
+		return 0, timeoutError{}
+	}
+}
+
+func (p *fakeModule) Write(data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if bytes.HasPrefix(data, []byte("AT")) {
+		p.commands = append(p.commands, strings.TrimSpace(string(data)))
+		p.rx <- []byte("\r\nOK\r\n")
+		return len(data), nil
+	}
+
+	var frames []frame
+	frames, p.pending = parseBasic(append(p.pending, data...))
+	for _, f := range frames {
+		p.frames = append(p.frames, f)
+		switch {
+		case f.control == ctrlSABM || f.control == ctrlDISC:
+			p.rx <- encode(Basic, f.dlci, ctrlUA|ctrlPF, false, nil)
+		case f.dlci == 0 && len(f.info) >= 2 && f.info[0] == msgCLD|0x03:
+			p.rx <- encode(Basic, 0, ctrlUIH, false, []byte{msgCLD | 0x01, 0x01})
+		}
+	}
+	return len(data), nil
+}
+
+func (p *fakeModule) Flush() error { return nil }
+
+func (p *fakeModule) Close() error {
+	p.once.Do(func() { close(p.closed) })
+	return nil
+}
+
+// send 在指定通道向复用器发送数据
+func (p *fakeModule) send(dlci int, data string) {
+	p.rx <- encode(Basic, dlci, ctrlUIH, false, []byte(data))
+}
+
+// flowControl 返回模块收到的指定通道 MSC 中的 FC 位序列
+func (p *fakeModule) flowControl(dlci int) []bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	list := []bool{}
+	for _, f := range p.frames {
+		info := f.info
+		if f.dlci == 0 && len(info) >= 4 && info[0]&^0x03 == msgMSC && int(info[2]>>2) == dlci {
+			list = append(list, info[3]&signalFC != 0)
+		}
+	}
+	return list
+}
+
+func TestOpenDefaultCommand(t *testing.T) {
+	p := newFakeModule()
+	m, err := Open(p, nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer m.Close()
+
+	if len(p.commands) != 1 || p.commands[0] != "AT+CMUX=0,0,,127" {
+		t.Fatalf("commands = %q", p.commands)
+	}
+}
+
+func TestStalledChannel(t *testing.T) {
+	p := newFakeModule()
+	m, err := Open(p, &Config{NoNegotiate: true, BufferSize: 64, ReadTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer m.Close()
+
+	stalled, err := m.Channel(1)
+	if err != nil {
+		t.Fatalf("Channel(1): %v", err)
+	}
+	other, err := m.Channel(2)
+	if err != nil {
+		t.Fatalf("Channel(2): %v", err)
+	}
+
+	// 无人读取的通道写满缓冲区后，其他通道仍能收到数据
+	for i := 0; i < 10; i++ {
+		p.send(1, strings.Repeat("x", 20))
+	}
+	p.send(2, "hello")
+
+	buf := make([]byte, 64)
+	n, err := other.Read(buf)
+	if err != nil || string(buf[:n]) != "hello" {
+		t.Fatalf("Read = %q, %v", buf[:n], err)
+	}
+
+	if dropped := stalled.Dropped(); dropped != 200-64 {
+		t.Fatalf("Dropped = %d", dropped)
+	}
+	if fc := p.flowControl(1); len(fc) != 2 || fc[0] || !fc[1] {
+		t.Fatalf("MSC FC = %v, want [false true]", fc)
+	}
+
+	// 读出数据后解除流控
+	total := 0
+	for total < 64 {
+		n, err := stalled.Read(buf)
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		total += n
+	}
+	if fc := p.flowControl(1); len(fc) != 3 || fc[2] {
+		t.Fatalf("MSC FC = %v, want [false true false]", fc)
+	}
+}
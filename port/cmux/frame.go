@@ -0,0 +1,212 @@
+package cmux
+
+import "bytes"
+
+// 帧标志
+const (
+	flagBasic    = 0xF9 // 基本模式
+	flagAdvanced = 0x7E // 高级模式
+	escape       = 0x7D // 高级模式转义字符
+)
+
+// 帧类型（控制字段，不含 P/F 位）
+const (
+	ctrlSABM = 0x2F // 建立连接
+	ctrlUA   = 0x63 // 确认
+	ctrlDM   = 0x0F // 断开模式（拒绝）
+	ctrlDISC = 0x43 // 断开连接
+	ctrlUIH  = 0xEF // 无编号信息帧，校验只覆盖头部
+	ctrlUI   = 0x03 // 无编号信息帧
+	ctrlPF   = 0x10 // P/F 位
+)
+
+// frame 解码后的帧
+type frame struct {
+	dlci    int
+	control byte // 不含 P/F 位
+	pf      bool
+	cr      bool
+	info    []byte
+}
+
+// fcsTable CRC-8 查表（多项式 x^8+x^2+x+1 的反射形式）
+var fcsTable [256]byte
+
+func init() {
+	for i := range fcsTable {
+		crc := byte(i)
+		for j := 0; j < 8; j++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xE0
+			} else {
+				crc >>= 1
+			}
+		}
+		fcsTable[i] = crc
+	}
+}
+
+// crc 计算 CRC-8 中间值
+func crc(data []byte) byte {
+	v := byte(0xFF)
+	for _, b := range data {
+		v = fcsTable[v^b]
+	}
+	return v
+}
+
+// fcs 计算帧校验序列
+func fcs(data []byte) byte {
+	return 0xFF - crc(data)
+}
+
+// checkFCS 校验帧，数据连同校验序列的 CRC 应为固定值
+func checkFCS(data []byte, value byte) bool {
+	return fcsTable[crc(data)^value] == 0xCF
+}
+
+// encode 编码帧，cr 为命令/响应位
+func encode(mode Mode, dlci int, control byte, cr bool, info []byte) []byte {
+	addr := byte(dlci<<2) | 0x01
+	if cr {
+		addr |= 0x02
+	}
+	header := []byte{addr, control}
+
+	if mode == Basic {
+		if len(info) <= 0x7F {
+			header = append(header, byte(len(info)<<1)|0x01)
+		} else {
+			header = append(header, byte(len(info)<<1), byte(len(info)>>7))
+		}
+	}
+
+	// UIH 帧的校验只覆盖头部
+	covered := header
+	if control&^ctrlPF != ctrlUIH {
+		covered = append(append([]byte{}, header...), info...)
+	}
+	sum := fcs(covered)
+
+	if mode == Basic {
+		out := make([]byte, 0, len(header)+len(info)+3)
+		out = append(out, flagBasic)
+		out = append(out, header...)
+		out = append(out, info...)
+		return append(out, sum, flagBasic)
+	}
+
+	body := append(append(header, info...), sum)
+	out := []byte{flagAdvanced}
+	for _, b := range body {
+		if b == flagAdvanced || b == escape {
+			out = append(out, escape, b^0x20)
+			continue
+		}
+		out = append(out, b)
+	}
+	return append(out, flagAdvanced)
+}
+
+// decodeHeader 解析地址和控制字段
+func decodeHeader(addr, control byte, info []byte) frame {
+	return frame{
+		dlci:    int(addr >> 2),
+		control: control &^ ctrlPF,
+		pf:      control&ctrlPF != 0,
+		cr:      addr&0x02 != 0,
+		info:    info,
+	}
+}
+
+// parseBasic 从缓冲区解析基本模式帧，返回完整的帧和剩余数据
+func parseBasic(buf []byte) ([]frame, []byte) {
+	frames := []frame{}
+	for {
+		// 定位起始标志，相邻帧可能共用标志
+		start := bytes.IndexByte(buf, flagBasic)
+		if start < 0 {
+			return frames, nil
+		}
+		buf = buf[start:]
+		for len(buf) > 1 && buf[1] == flagBasic {
+			buf = buf[1:]
+		}
+		if len(buf) < 4 {
+			return frames, buf
+		}
+
+		// 长度字段为 1 或 2 字节
+		header := 3
+		length := int(buf[3] >> 1)
+		if buf[3]&0x01 == 0 {
+			if len(buf) < 5 {
+				return frames, buf
+			}
+			header = 4
+			length |= int(buf[4]) << 7
+		}
+		total := 1 + header + length + 2
+		if len(buf) < total {
+			return frames, buf
+		}
+
+		info := buf[1+header : 1+header+length]
+		sum := buf[1+header+length]
+		covered := buf[1 : 1+header]
+		if buf[2]&^ctrlPF != ctrlUIH {
+			covered = buf[1 : 1+header+length]
+		}
+		if buf[total-1] != flagBasic || !checkFCS(covered, sum) {
+			// 校验失败，从下一个字节重新同步
+			buf = buf[1:]
+			continue
+		}
+
+		frames = append(frames, decodeHeader(buf[1], buf[2], append([]byte{}, info...)))
+		// 结束标志可作为下一帧的起始标志
+		buf = buf[total-1:]
+	}
+}
+
+// parseAdvanced 从缓冲区解析高级模式帧，返回完整的帧和剩余数据
+func parseAdvanced(buf []byte) ([]frame, []byte) {
+	frames := []frame{}
+	for {
+		start := bytes.IndexByte(buf, flagAdvanced)
+		if start < 0 {
+			return frames, nil
+		}
+		buf = buf[start:]
+		end := bytes.IndexByte(buf[1:], flagAdvanced)
+		if end < 0 {
+			return frames, buf
+		}
+		raw := buf[1 : end+1]
+		buf = buf[end+1:]
+
+		// 去除转义
+		body := make([]byte, 0, len(raw))
+		for i := 0; i < len(raw); i++ {
+			if raw[i] == escape && i+1 < len(raw) {
+				i++
+				body = append(body, raw[i]^0x20)
+				continue
+			}
+			body = append(body, raw[i])
+		}
+		if len(body) < 3 {
+			continue
+		}
+
+		sum := body[len(body)-1]
+		covered := body[:2]
+		if body[1]&^ctrlPF != ctrlUIH {
+			covered = body[:len(body)-1]
+		}
+		if !checkFCS(covered, sum) {
+			continue
+		}
+		frames = append(frames, decodeHeader(body[0], body[1], body[2:len(body)-1]))
+	}
+}
@@ -0,0 +1,146 @@
+// Package record 提供串口通信的录制和回放
+//
+// Recorder 包装任意 at.Port，将每次读写连同时间戳以 JSON lines 格式写入文件；
+// Replay 按录制顺序回放，可直接传给 at.New 在本地复现现场问题。
+package record
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rehiy/modem/at"
+)
+
+// 读写方向
+const (
+	DirRead  = "r" // 从串口读取
+	DirWrite = "w" // 向串口写入
+	DirDTR   = "d" // 设置 DTR，数据为 "1"（拉高）或 "0"（拉低）
+)
+
+// Entry 一次读写记录
+type Entry struct {
+	Time time.Time `json:"time"`          // 读写完成的时间
+	Dir  string    `json:"dir"`           // 方向，DirRead、DirWrite 或 DirDTR
+	Data string    `json:"data"`          // 数据，每个字节对应一个 U+0000 至 U+00FF 字符，非 ASCII 字节不会丢失
+	Err  string    `json:"err,omitempty"` // 读写错误，读取超时不记录
+}
+
+// Bytes 返回记录的原始字节
+func (e Entry) Bytes() []byte {
+	b := make([]byte, 0, len(e.Data))
+	for _, r := range e.Data {
+		b = append(b, byte(r))
+	}
+	return b
+}
+
+// newEntry 创建记录，按字节编码数据
+func newEntry(dir string, data []byte, err error) Entry {
+	r := make([]rune, len(data))
+	for i, b := range data {
+		r[i] = rune(b)
+	}
+
+	entry := Entry{Time: time.Now(), Dir: dir, Data: string(r)}
+	if err != nil {
+		entry.Err = err.Error()
+	}
+	return entry
+}
+
+// Load 读取 JSON lines 格式的录制文件
+func Load(r io.Reader) ([]Entry, error) {
+	entries := []Entry{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Recorder 录制串口读写
+type Recorder struct {
+	port at.Port
+	enc  *json.Encoder
+	mu   sync.Mutex // 保护 enc，读写来自不同协程
+}
+
+// NewRecorder 包装串口，读写记录写入 w；w 由调用方负责关闭
+func NewRecorder(port at.Port, w io.Writer) *Recorder {
+	return &Recorder{port: port, enc: json.NewEncoder(w)}
+}
+
+// Read 读取数据并记录，读取超时不记录
+func (r *Recorder) Read(buf []byte) (int, error) {
+	n, err := r.port.Read(buf)
+	if n > 0 || (err != nil && !isTimeout(err)) {
+		r.record(newEntry(DirRead, buf[:n], err))
+	}
+	return n, err
+}
+
+// Write 写入数据并记录
+func (r *Recorder) Write(data []byte) (int, error) {
+	n, err := r.port.Write(data)
+	r.record(newEntry(DirWrite, data[:n], err))
+	return n, err
+}
+
+// SetDTR 设置被包装串口的 DTR 信号并记录，串口不支持时返回 ErrNoDTR
+func (r *Recorder) SetDTR(on bool) error {
+	setter, ok := r.port.(dtrSetter)
+	if !ok {
+		return ErrNoDTR
+	}
+	err := setter.SetDTR(on)
+	r.record(newEntry(DirDTR, dtrData(on), err))
+	return err
+}
+
+// Flush 清空被包装串口的缓冲区
+func (r *Recorder) Flush() error {
+	return r.port.Flush()
+}
+
+// Close 关闭被包装的串口
+func (r *Recorder) Close() error {
+	return r.port.Close()
+}
+
+// record 写入一条记录，写入失败时放弃该记录，不影响串口读写
+func (r *Recorder) record(entry Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enc.Encode(entry)
+}
+
+// dtrSetter 支持控制 DTR 信号的串口
+type dtrSetter interface {
+	SetDTR(on bool) error
+}
+
+// dtrData 返回 DTR 记录的数据
+func dtrData(on bool) []byte {
+	if on {
+		return []byte("1")
+	}
+	return []byte("0")
+}
+
+// isTimeout 判断是否为读取超时
+func isTimeout(err error) bool {
+	t, ok := err.(interface{ Timeout() bool })
+	return ok && t.Timeout()
+}
@@ -0,0 +1,116 @@
+package record_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rehiy/modem/at"
+	"github.com/rehiy/modem/port/record"
+	"github.com/rehiy/modem/port/sim"
+)
+
+// dtrModem 支持 DTR 的模拟器，拉低 DTR 时以 NO CARRIER 应答
+type dtrModem struct {
+	*sim.Modem
+}
+
+func (s dtrModem) SetDTR(on bool) error {
+	if !on {
+		s.URC("NO CARRIER")
+	}
+	return nil
+}
+
+// newDevice 创建使用 DTR 退出数据模式的设备，测试结束时关闭
+func newDevice(t *testing.T, port at.Port) *at.Device {
+	d := at.New(port, nil, &at.Config{
+		Printf:      func(string, ...any) {},
+		Timeout:     200 * time.Millisecond,
+		EscapeGuard: 10 * time.Millisecond,
+		EscapeDTR:   true,
+	})
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+// session 执行一次固定的操作，返回各步骤的响应
+func session(t *testing.T, d *at.Device) []string {
+	t.Helper()
+
+	responses, err := d.SendCommand("AT+CSQ")
+	if err != nil {
+		t.Fatalf("AT+CSQ: %v", err)
+	}
+	conn, err := d.EnterDataMode("ATD*99#")
+	if err != nil {
+		t.Fatalf("EnterDataMode: %v", err)
+	}
+	responses = append(responses, conn.Connect())
+	if err := conn.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := d.Test(); err != nil {
+		t.Fatalf("command after data mode: %v", err)
+	}
+	return responses
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	s := sim.New(&sim.Config{Echo: true, ReadTimeout: 10 * time.Millisecond})
+	s.Handle(`AT\+CSQ`, "+CSQ: 20,99", "OK")
+	s.Handle(`ATD\*99#`, "CONNECT 115200")
+
+	var buf bytes.Buffer
+	recorded := session(t, newDevice(t, record.NewRecorder(dtrModem{s}, &buf)))
+	want := []string{"+CSQ: 20,99", "OK", "CONNECT 115200"}
+	if !reflect.DeepEqual(recorded, want) {
+		t.Fatalf("recorded responses = %q, want %q", recorded, want)
+	}
+
+	entries, err := record.Load(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	dtr := ""
+	for _, entry := range entries {
+		if entry.Dir == record.DirDTR {
+			dtr += entry.Data
+		}
+	}
+	if dtr != "01" {
+		t.Fatalf("DTR entries = %q, want \"01\"", dtr)
+	}
+
+	// 回放得到与录制时相同的响应，并处理完全部记录
+	replay := record.NewReplay(entries, &record.ReplayConfig{Strict: true})
+	if replayed := session(t, newDevice(t, replay)); !reflect.DeepEqual(replayed, recorded) {
+		t.Fatalf("replayed responses = %q, want %q", replayed, recorded)
+	}
+	select {
+	case <-replay.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("replay not done")
+	}
+
+	// 严格模式下写入与录制不一致时返回 ErrMismatch
+	d := newDevice(t, record.NewReplay(entries, &record.ReplayConfig{Strict: true}))
+	if _, err := d.SendCommand("AT+CGMI"); !errors.Is(err, record.ErrMismatch) {
+		t.Fatalf("SendCommand = %v, want ErrMismatch", err)
+	}
+}
+
+func TestRecorderWithoutDTR(t *testing.T) {
+	s := sim.New(&sim.Config{})
+	defer s.Close()
+
+	var buf bytes.Buffer
+	if err := record.NewRecorder(s, &buf).SetDTR(false); !errors.Is(err, record.ErrNoDTR) {
+		t.Fatalf("SetDTR = %v, want ErrNoDTR", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("recorded %q", buf.String())
+	}
+}
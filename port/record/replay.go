@@ -0,0 +1,219 @@
+package record
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrClosed 回放已关闭
+	ErrClosed = errors.New("record: closed")
+	// ErrMismatch 严格模式下写入的数据与录制不一致
+	ErrMismatch = errors.New("record: write mismatch")
+	// ErrNoDTR 被录制的串口不支持 DTR
+	ErrNoDTR = errors.New("record: port does not support DTR")
+)
+
+// timeoutError 读取超时，Timeout() 为 true
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "record: read timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// ReplayConfig 回放配置
+type ReplayConfig struct {
+	Timing      bool          // 按录制时的间隔返回读取数据，默认立即返回
+	Strict      bool          // 写入的数据必须与录制一致，否则返回 ErrMismatch
+	ReadTimeout time.Duration // 无数据可读时的超时，默认 100 毫秒
+}
+
+// Replay 按录制顺序回放的串口
+//
+// 读取按顺序返回录制的数据，遇到尚未发生的写入时等待，直到调用方写入对应的数据，
+// 从而保证响应不会早于命令到达；录制的读取错误（如串口失效）会原样返回。
+type Replay struct {
+	config   ReplayConfig
+	entries  []Entry
+	consumed []bool        // 写入记录是否已被匹配
+	pos      int           // 下一条待处理的记录
+	rest     []byte        // 上次未读完的数据
+	last     time.Time     // 上一条已返回记录的录制时间
+	changed  chan struct{} // 状态变化时关闭并替换
+	done     chan struct{} // 全部记录处理完毕时关闭
+	closed   bool
+	mu       sync.Mutex
+}
+
+// NewReplay 创建回放串口
+func NewReplay(entries []Entry, config *ReplayConfig) *Replay {
+	if config == nil {
+		config = &ReplayConfig{}
+	}
+
+	r := &Replay{
+		config:   *config,
+		entries:  entries,
+		consumed: make([]bool, len(entries)),
+		changed:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if r.config.ReadTimeout == 0 {
+		r.config.ReadTimeout = 100 * time.Millisecond
+	}
+	r.advance()
+
+	return r
+}
+
+// Done 返回全部记录处理完毕时关闭的通道
+func (r *Replay) Done() <-chan struct{} {
+	return r.done
+}
+
+// Read 按顺序返回录制的读取数据
+func (r *Replay) Read(buf []byte) (int, error) {
+	timer := time.NewTimer(r.config.ReadTimeout)
+	defer timer.Stop()
+
+	for {
+		r.mu.Lock()
+		if r.closed {
+			r.mu.Unlock()
+			return 0, ErrClosed
+		}
+		if len(r.rest) > 0 {
+			n := copy(buf, r.rest)
+			r.rest = r.rest[n:]
+			r.mu.Unlock()
+			return n, nil
+		}
+
+		// 下一条是读取记录时返回，是写入记录时等待调用方写入
+		if r.pos < len(r.entries) && r.entries[r.pos].Dir == DirRead {
+			entry := r.entries[r.pos]
+			wait := time.Duration(0)
+			if r.config.Timing && !r.last.IsZero() {
+				wait = entry.Time.Sub(r.last)
+			}
+			r.mu.Unlock()
+
+			if wait > 0 {
+				time.Sleep(wait)
+			}
+			return r.take(buf, entry)
+		}
+		changed := r.changed
+		r.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return 0, timeoutError{}
+		}
+	}
+}
+
+// take 返回读取记录的数据或错误，并前进到下一条记录
+func (r *Replay) take(buf []byte, entry Entry) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.last = entry.Time
+	r.pos++
+	r.advance()
+
+	data := entry.Bytes()
+	n := copy(buf, data)
+	r.rest = data[n:]
+	if entry.Err != "" && len(r.rest) == 0 {
+		return n, errors.New(entry.Err)
+	}
+	return n, nil
+}
+
+// Write 匹配下一条未匹配的写入记录，其前面尚未读取的数据仍按顺序返回
+func (r *Replay) Write(data []byte) (int, error) {
+	if err := r.match(DirWrite, data); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// SetDTR 匹配下一条未匹配的 DTR 记录，与写入记录一样按顺序放行之后的读取数据
+func (r *Replay) SetDTR(on bool) error {
+	return r.match(DirDTR, dtrData(on))
+}
+
+// match 匹配下一条指定方向的未匹配记录，返回录制的错误
+func (r *Replay) match(dir string, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrClosed
+	}
+
+	for i := r.pos; i < len(r.entries); i++ {
+		entry := r.entries[i]
+		if entry.Dir != dir || r.consumed[i] {
+			continue
+		}
+		if r.config.Strict && string(entry.Bytes()) != string(data) {
+			return fmt.Errorf("%w: expected %q, got %q", ErrMismatch, entry.Bytes(), data)
+		}
+		r.consumed[i] = true
+		r.advance()
+		if entry.Err != "" {
+			return errors.New(entry.Err)
+		}
+		return nil
+	}
+
+	if r.config.Strict {
+		return fmt.Errorf("%w: unexpected %q", ErrMismatch, data)
+	}
+	return nil
+}
+
+// Flush 丢弃上次未读完的数据
+func (r *Replay) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rest = nil
+	return nil
+}
+
+// Close 关闭回放
+func (r *Replay) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.closed {
+		r.closed = true
+		r.notify()
+	}
+	return nil
+}
+
+// advance 跳过已匹配的写入记录，调用方需持有锁
+func (r *Replay) advance() {
+	for r.pos < len(r.entries) && r.consumed[r.pos] {
+		r.pos++
+	}
+	if r.pos == len(r.entries) {
+		select {
+		case <-r.done:
+		default:
+			close(r.done)
+		}
+	}
+	r.notify()
+}
+
+// notify 唤醒等待中的读取，调用方需持有锁
+func (r *Replay) notify() {
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
@@ -0,0 +1,464 @@
+// Package rfc2217 提供基于 Telnet COM 端口控制协议（RFC 2217）的 at.Port 实现
+//
+// 适用于 ser2net telnet 模式、工业串口服务器等远程串口，连接时协商波特率、
+// 数据格式和流控，读写时处理 Telnet 命令和 IAC 转义。
+package rfc2217
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrClosed 对端关闭了连接
+	ErrClosed = errors.New("rfc2217: connection closed by peer")
+	// ErrRefused 服务器拒绝 COM 端口控制选项
+	ErrRefused = errors.New("rfc2217: com port option refused")
+	// ErrSettings 服务器确认的串口参数与请求不一致
+	ErrSettings = errors.New("rfc2217: settings not accepted")
+)
+
+// Telnet 命令（RFC 854）
+const (
+	cmdSE   = 240
+	cmdSB   = 250
+	cmdWILL = 251
+	cmdWONT = 252
+	cmdDO   = 253
+	cmdDONT = 254
+	cmdIAC  = 255
+)
+
+// Telnet 选项
+const (
+	optBinary  = 0  // 二进制传输（RFC 856）
+	optSGA     = 3  // 抑制继续进行（RFC 858）
+	optComPort = 44 // COM 端口控制（RFC 2217）
+)
+
+// COM 端口控制子命令，服务器响应为对应值加 100
+const (
+	comSetBaudRate       = 1
+	comSetDataSize       = 2
+	comSetParity         = 3
+	comSetStopSize       = 4
+	comSetControl        = 5
+	comNotifyLineState   = 6
+	comNotifyModemState  = 7
+	comSetLineStateMask  = 10
+	comSetModemStateMask = 11
+	comPurgeData         = 12
+	comServerOffset      = 100
+)
+
+// 请求的数据格式 8N1
+const (
+	dataSize8  = 8
+	parityNone = 1
+	stopSize1  = 1
+)
+
+// SET-CONTROL 取值
+const (
+	controlNoFlow   = 1
+	controlHardware = 3
+	controlDTROn    = 8
+	controlDTROff   = 9
+	controlRTSOn    = 11
+	controlRTSOff   = 12
+)
+
+// 线路状态（NOTIFY-LINESTATE）
+const (
+	LineTimeout      = 0x80 // 发送移位寄存器超时
+	LineTxEmpty      = 0x40 // 发送移位寄存器空
+	LineTxHoldEmpty  = 0x20 // 发送保持寄存器空
+	LineBreak        = 0x10 // 检测到中断信号
+	LineFramingError = 0x08 // 帧错误
+	LineParityError  = 0x04 // 校验错误
+	LineOverrunError = 0x02 // 溢出错误
+	LineDataReady    = 0x01 // 有数据待读
+)
+
+// 调制解调器状态（NOTIFY-MODEMSTATE）
+const (
+	ModemDCD = 0x80 // 载波检测
+	ModemRI  = 0x40 // 振铃指示
+	ModemDSR = 0x20 // 数据设备就绪
+	ModemCTS = 0x10 // 允许发送
+)
+
+// Config 连接配置，数据格式固定为 8N1
+type Config struct {
+	Address     string        // 服务器地址，如 192.168.1.10:2001
+	Baud        int           // 波特率，默认 115200
+	FlowControl bool          // 启用 RTS/CTS 硬件流控
+	DialTimeout time.Duration // 连接和协商超时，默认 10 秒
+	ReadTimeout time.Duration // 读取超时，为 0 时一直等待数据
+}
+
+// 接收解析状态
+const (
+	stateData = iota
+	stateIAC
+	stateOption
+	stateSub
+	stateSubIAC
+)
+
+// Port RFC 2217 连接
+type Port struct {
+	conn    net.Conn
+	config  Config
+	raw     []byte     // 原始读取缓冲区
+	pending []byte     // 已解析但尚未返回的数据
+	state   int        // 接收解析状态
+	verb    byte       // 当前选项协商命令
+	sub     []byte     // 当前子协商内容
+	wmu     sync.Mutex // 保护写入，避免协商应答与数据交错
+
+	mu         sync.Mutex    // 保护以下状态
+	local      map[byte]bool // 本端已启用的选项
+	remote     map[byte]bool // 对端已启用的选项
+	configured bool          // 是否已发送串口参数
+	refused    bool          // 服务器是否拒绝 COM 端口控制
+	baud       int           // 服务器确认的波特率，0 表示尚未确认
+	line       [3]byte       // 服务器确认的数据位、校验位、停止位，0 表示尚未确认
+	lineState  byte          // 最近一次线路状态
+	modemState byte          // 最近一次调制解调器状态
+}
+
+// Dial 连接串口服务器并协商串口参数
+func Dial(config *Config) (*Port, error) {
+	cfg := *config
+	if cfg.Baud == 0 {
+		cfg.Baud = 115200
+	}
+	if cfg.DialTimeout == 0 {
+		cfg.DialTimeout = 10 * time.Second
+	}
+
+	conn, err := net.DialTimeout("tcp", cfg.Address, cfg.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Port{
+		conn:   conn,
+		config: cfg,
+		raw:    make([]byte, 1024),
+		local:  map[byte]bool{},
+		remote: map[byte]bool{},
+	}
+	if err := p.negotiate(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// negotiate 请求启用选项，等待服务器确认波特率和数据格式并与请求比较
+func (p *Port) negotiate() error {
+	err := p.writeRaw([]byte{
+		cmdIAC, cmdWILL, optComPort,
+		cmdIAC, cmdWILL, optBinary,
+		cmdIAC, cmdDO, optBinary,
+		cmdIAC, cmdWILL, optSGA,
+		cmdIAC, cmdDO, optSGA,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 已主动请求对端启用的选项，收到 WILL 时视为确认，无需再应答
+	p.mu.Lock()
+	p.remote[optBinary] = true
+	p.remote[optSGA] = true
+	p.mu.Unlock()
+
+	if err := p.conn.SetReadDeadline(time.Now().Add(p.config.DialTimeout)); err != nil {
+		return err
+	}
+	for {
+		p.mu.Lock()
+		baud, line, refused := p.baud, p.line, p.refused
+		p.mu.Unlock()
+		if refused {
+			return ErrRefused
+		}
+		if baud != 0 && line[0] != 0 && line[1] != 0 && line[2] != 0 {
+			if baud != p.config.Baud {
+				return fmt.Errorf("%w: baud %d, want %d", ErrSettings, baud, p.config.Baud)
+			}
+			if line != [3]byte{dataSize8, parityNone, stopSize1} {
+				return fmt.Errorf("%w: data size %d, parity %d, stop size %d, want 8N1", ErrSettings, line[0], line[1], line[2])
+			}
+			break
+		}
+		if err := p.fill(); err != nil {
+			return fmt.Errorf("rfc2217: negotiate: %w", err)
+		}
+	}
+	return p.conn.SetReadDeadline(time.Time{})
+}
+
+// configure 发送串口参数，在服务器同意 COM 端口控制后调用
+func (p *Port) configure() error {
+	baud := make([]byte, 4)
+	binary.BigEndian.PutUint32(baud, uint32(p.config.Baud))
+
+	control := byte(controlNoFlow)
+	if p.config.FlowControl {
+		control = controlHardware
+	}
+
+	frames := [][]byte{
+		subFrame(comSetBaudRate, baud...),
+		subFrame(comSetDataSize, dataSize8),
+		subFrame(comSetParity, parityNone),
+		subFrame(comSetStopSize, stopSize1),
+		subFrame(comSetControl, control),
+		subFrame(comSetLineStateMask, LineBreak|LineFramingError|LineParityError|LineOverrunError),
+		subFrame(comSetModemStateMask, ModemDCD|ModemRI|ModemDSR|ModemCTS),
+	}
+	return p.writeRaw(bytes.Join(frames, nil))
+}
+
+// Read 读取数据，超时返回 Timeout() 为 true 的错误，对端关闭时返回 ErrClosed
+func (p *Port) Read(buf []byte) (int, error) {
+	if p.config.ReadTimeout > 0 {
+		if err := p.conn.SetReadDeadline(time.Now().Add(p.config.ReadTimeout)); err != nil {
+			return 0, err
+		}
+	}
+
+	for len(p.pending) == 0 {
+		if err := p.fill(); err != nil {
+			n := copy(buf, p.pending)
+			p.pending = p.pending[n:]
+			return n, err
+		}
+	}
+
+	n := copy(buf, p.pending)
+	p.pending = p.pending[n:]
+	return n, nil
+}
+
+// fill 从连接读取一次并解析，数据追加到 pending
+func (p *Port) fill() error {
+	n, err := p.conn.Read(p.raw)
+	p.decode(p.raw[:n])
+	if err == io.EOF {
+		// 对端关闭，返回包内错误便于调用方识别
+		return ErrClosed
+	}
+	return err
+}
+
+// decode 解析接收到的字节，分离 Telnet 命令和数据
+func (p *Port) decode(data []byte) {
+	for _, b := range data {
+		switch p.state {
+		case stateData:
+			if b == cmdIAC {
+				p.state = stateIAC
+				continue
+			}
+			p.pending = append(p.pending, b)
+
+		case stateIAC:
+			switch b {
+			case cmdIAC:
+				p.pending = append(p.pending, b)
+				p.state = stateData
+			case cmdWILL, cmdWONT, cmdDO, cmdDONT:
+				p.verb = b
+				p.state = stateOption
+			case cmdSB:
+				p.sub = p.sub[:0]
+				p.state = stateSub
+			default:
+				// NOP、GA 等无参数命令
+				p.state = stateData
+			}
+
+		case stateOption:
+			p.handleOption(p.verb, b)
+			p.state = stateData
+
+		case stateSub:
+			if b == cmdIAC {
+				p.state = stateSubIAC
+				continue
+			}
+			p.sub = append(p.sub, b)
+
+		case stateSubIAC:
+			switch b {
+			case cmdIAC:
+				p.sub = append(p.sub, b)
+				p.state = stateSub
+			case cmdSE:
+				p.handleSub(p.sub)
+				p.state = stateData
+			default:
+				p.state = stateData
+			}
+		}
+	}
+}
+
+// handleOption 应答选项协商，只在状态变化时应答以免循环
+func (p *Port) handleOption(verb, opt byte) {
+	supported := opt == optBinary || opt == optSGA || opt == optComPort
+
+	p.mu.Lock()
+	var reply []byte
+	configure := false
+	switch verb {
+	case cmdDO:
+		if !supported {
+			reply = []byte{cmdIAC, cmdWONT, opt}
+			break
+		}
+		// 已主动发送过 WILL，这里只需确认
+		p.local[opt] = true
+		if opt == optComPort && !p.configured {
+			p.configured = true
+			configure = true
+		}
+
+	case cmdDONT:
+		if opt == optComPort {
+			p.refused = true
+		}
+		p.local[opt] = false
+
+	case cmdWILL:
+		switch {
+		case opt == optComPort || !supported:
+			// COM 端口选项只由客户端启用，拒绝服务器端的请求
+			reply = []byte{cmdIAC, cmdDONT, opt}
+		case !p.remote[opt]:
+			p.remote[opt] = true
+			reply = []byte{cmdIAC, cmdDO, opt}
+		}
+
+	case cmdWONT:
+		p.remote[opt] = false
+	}
+	p.mu.Unlock()
+
+	if reply != nil {
+		p.writeRaw(reply)
+	}
+	if configure {
+		p.configure()
+	}
+}
+
+// handleSub 处理服务器的 COM 端口控制子协商
+func (p *Port) handleSub(sub []byte) {
+	if len(sub) < 3 || sub[0] != optComPort {
+		return
+	}
+
+	cmd, value := sub[1], sub[2:]
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch cmd {
+	case comServerOffset + comSetBaudRate:
+		if len(value) >= 4 {
+			p.baud = int(binary.BigEndian.Uint32(value))
+		}
+	case comServerOffset + comSetDataSize:
+		p.line[0] = value[0]
+	case comServerOffset + comSetParity:
+		p.line[1] = value[0]
+	case comServerOffset + comSetStopSize:
+		p.line[2] = value[0]
+	case comServerOffset + comNotifyLineState:
+		p.lineState = value[0]
+	case comServerOffset + comNotifyModemState:
+		p.modemState = value[0]
+	}
+}
+
+// Write 写入数据，数据中的 IAC 字节会被转义
+func (p *Port) Write(data []byte) (int, error) {
+	escaped := bytes.ReplaceAll(data, []byte{cmdIAC}, []byte{cmdIAC, cmdIAC})
+	if err := p.writeRaw(escaped); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// Flush 请求服务器清空串口收发缓冲区
+func (p *Port) Flush() error {
+	return p.writeRaw(subFrame(comPurgeData, 3))
+}
+
+// SetDTR 设置远端串口的 DTR 信号
+func (p *Port) SetDTR(on bool) error {
+	if on {
+		return p.writeRaw(subFrame(comSetControl, controlDTROn))
+	}
+	return p.writeRaw(subFrame(comSetControl, controlDTROff))
+}
+
+// SetRTS 设置远端串口的 RTS 信号
+func (p *Port) SetRTS(on bool) error {
+	if on {
+		return p.writeRaw(subFrame(comSetControl, controlRTSOn))
+	}
+	return p.writeRaw(subFrame(comSetControl, controlRTSOff))
+}
+
+// Baud 返回服务器确认的波特率
+func (p *Port) Baud() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.baud
+}
+
+// LineState 返回服务器最近通知的线路状态，见 Line* 常量
+func (p *Port) LineState() byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lineState
+}
+
+// ModemState 返回服务器最近通知的调制解调器状态，见 Modem* 常量
+func (p *Port) ModemState() byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.modemState
+}
+
+// Close 关闭连接
+func (p *Port) Close() error {
+	return p.conn.Close()
+}
+
+// writeRaw 写入已编码的字节
+func (p *Port) writeRaw(data []byte) error {
+	p.wmu.Lock()
+	defer p.wmu.Unlock()
+	_, err := p.conn.Write(data)
+	return err
+}
+
+// subFrame 编码 COM 端口控制子协商，参数中的 IAC 字节会被转义
+func subFrame(cmd byte, value ...byte) []byte {
+	frame := []byte{cmdIAC, cmdSB, optComPort, cmd}
+	frame = append(frame, bytes.ReplaceAll(value, []byte{cmdIAC}, []byte{cmdIAC, cmdIAC})...)
+	return append(frame, cmdIAC, cmdSE)
+}
@@ -0,0 +1,280 @@
+package rfc2217
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer 模拟 RFC 2217 串口服务器：同意所有选项，按请求确认串口参数，记录收到的数据
+type fakeServer struct {
+	ln       net.Listener
+	refuse   bool            // 拒绝 COM 端口控制
+	replies  map[byte][]byte // 按子命令覆盖确认的参数值
+	conns    chan net.Conn
+	mu       sync.Mutex
+	accepted net.Conn // 已接受的连接，测试结束时关闭
+	raw      []byte   // 收到的原始字节
+	data     []byte   // 去除 Telnet 命令后的数据
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	s := &fakeServer{ln: ln, replies: map[byte][]byte{}, conns: make(chan net.Conn, 1)}
+	t.Cleanup(func() {
+		ln.Close()
+		s.mu.Lock()
+		if s.accepted != nil {
+			s.accepted.Close()
+		}
+		s.mu.Unlock()
+	})
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.accepted = conn
+		s.mu.Unlock()
+		s.conns <- conn
+		s.serve(conn)
+	}()
+	return s
+}
+
+// dial 以 8N1 连接服务器
+func (s *fakeServer) dial(baud int) (*Port, error) {
+	return Dial(&Config{Address: s.ln.Addr().String(), Baud: baud, DialTimeout: time.Second})
+}
+
+// conn 返回已接受的连接
+func (s *fakeServer) conn(t *testing.T) net.Conn {
+	t.Helper()
+	select {
+	case conn := <-s.conns:
+		return conn
+	case <-time.After(time.Second):
+		t.Fatal("no connection")
+		return nil
+	}
+}
+
+// serve 解析客户端发送的 Telnet 命令并应答
+func (s *fakeServer) serve(conn net.Conn) {
+	buf := make([]byte, 1024)
+	state := stateData
+	var verb byte
+	var sub []byte
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.raw = append(s.raw, buf[:n]...)
+		s.mu.Unlock()
+
+		for _, b := range buf[:n] {
+			switch state {
+			case stateData:
+				if b == cmdIAC {
+					state = stateIAC
+					continue
+				}
+				s.mu.Lock()
+				s.data = append(s.data, b)
+				s.mu.Unlock()
+			case stateIAC:
+				switch b {
+				case cmdIAC:
+					s.mu.Lock()
+					s.data = append(s.data, b)
+					s.mu.Unlock()
+					state = stateData
+				case cmdWILL, cmdDO:
+					verb, state = b, stateOption
+				case cmdSB:
+					sub, state = sub[:0], stateSub
+				default:
+					state = stateData
+				}
+			case stateOption:
+				s.option(conn, verb, b)
+				state = stateData
+			case stateSub:
+				if b == cmdIAC {
+					state = stateSubIAC
+					continue
+				}
+				sub = append(sub, b)
+			case stateSubIAC:
+				if b == cmdIAC {
+					sub = append(sub, b)
+					state = stateSub
+					continue
+				}
+				s.sub(conn, append([]byte{}, sub...))
+				state = stateData
+			}
+		}
+	}
+}
+
+// option 同意客户端请求的选项
+func (s *fakeServer) option(conn net.Conn, verb, opt byte) {
+	switch {
+	case verb == cmdWILL && opt == optComPort && s.refuse:
+		conn.Write([]byte{cmdIAC, cmdDONT, opt})
+	case verb == cmdWILL:
+		conn.Write([]byte{cmdIAC, cmdDO, opt})
+	case verb == cmdDO:
+		conn.Write([]byte{cmdIAC, cmdWILL, opt})
+	}
+}
+
+// sub 以请求的值（或覆盖值）确认串口参数
+func (s *fakeServer) sub(conn net.Conn, sub []byte) {
+	s.mu.Lock()
+	reply, ok := s.replies[sub[1]]
+	s.mu.Unlock()
+
+	switch sub[1] {
+	case comSetBaudRate, comSetDataSize, comSetParity, comSetStopSize:
+		if !ok {
+			reply = sub[2:]
+		}
+		conn.Write(subFrame(comServerOffset+sub[1], reply...))
+	}
+}
+
+// received 返回收到的原始字节和数据
+func (s *fakeServer) received() ([]byte, []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]byte{}, s.raw...), append([]byte{}, s.data...)
+}
+
+// baudValue 编码波特率
+func baudValue(baud int) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(baud))
+	return b
+}
+
+func TestDialAndEscape(t *testing.T) {
+	s := newFakeServer(t)
+	p, err := s.dial(115200)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer p.Close()
+	conn := s.conn(t)
+
+	if p.Baud() != 115200 {
+		t.Fatalf("Baud = %d", p.Baud())
+	}
+
+	// 协商时依次请求选项并发送 8N1
+	raw, _ := s.received()
+	prefix := []byte{cmdIAC, cmdWILL, optComPort, cmdIAC, cmdWILL, optBinary}
+	if !bytes.HasPrefix(raw, prefix) {
+		t.Fatalf("raw = % x", raw)
+	}
+	for _, frame := range [][]byte{
+		subFrame(comSetBaudRate, baudValue(115200)...),
+		subFrame(comSetDataSize, 8),
+		subFrame(comSetParity, 1),
+		subFrame(comSetStopSize, 1),
+	} {
+		if !bytes.Contains(raw, frame) {
+			t.Fatalf("raw = % x, missing % x", raw, frame)
+		}
+	}
+
+	// 写入的 IAC 字节被转义
+	if _, err := p.Write([]byte{'A', cmdIAC, 'B'}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		raw, data := s.received()
+		if bytes.HasSuffix(data, []byte{'A', cmdIAC, 'B'}) {
+			if !bytes.HasSuffix(raw, []byte{'A', cmdIAC, cmdIAC, 'B'}) {
+				t.Fatalf("raw = % x", raw)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("data = % x", data)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 读取时剥离 Telnet 命令、还原转义的 IAC，并记录状态通知
+	conn.Write([]byte{'O', cmdIAC, cmdIAC, 'K'})
+	conn.Write([]byte{cmdIAC, 241}) // NOP
+	conn.Write(subFrame(comServerOffset+comNotifyModemState, ModemDCD))
+	conn.Write([]byte("\r\n"))
+
+	got := []byte{}
+	buf := make([]byte, 16)
+	for len(got) < 5 {
+		n, err := p.Read(buf)
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		got = append(got, buf[:n]...)
+	}
+	if string(got) != "O\xffK\r\n" {
+		t.Fatalf("Read = %q", got)
+	}
+	if p.ModemState() != ModemDCD {
+		t.Fatalf("ModemState = %#x", p.ModemState())
+	}
+
+	// 对端关闭时返回 ErrClosed
+	conn.Close()
+	if _, err := p.Read(buf); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Read after close = %v, want ErrClosed", err)
+	}
+}
+
+func TestDialSettingsMismatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		cmd   byte
+		reply []byte
+	}{
+		{"baud", comSetBaudRate, baudValue(9600)},
+		{"parity", comSetParity, []byte{2}},
+		{"stop size", comSetStopSize, []byte{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFakeServer(t)
+			s.replies[tt.cmd] = tt.reply
+			if _, err := s.dial(115200); !errors.Is(err, ErrSettings) {
+				t.Fatalf("Dial = %v, want ErrSettings", err)
+			}
+		})
+	}
+}
+
+func TestDialRefused(t *testing.T) {
+	s := newFakeServer(t)
+	s.refuse = true
+	if _, err := s.dial(115200); !errors.Is(err, ErrRefused) {
+		t.Fatalf("Dial = %v, want ErrRefused", err)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build linux && !mips && !mipsle && !mips64 && !mips64le && !ppc64 && !ppc64le
+
+package serial
+
+// syscall 包在部分架构上未导出的 termios 常量
+const (
+	tcflsh = 0x540B
+	cbaud  = 0x100F
+)
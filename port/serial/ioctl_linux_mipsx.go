@@ -0,0 +1,9 @@
+//go:build linux && (mips || mipsle || mips64 || mips64le)
+
+package serial
+
+// syscall 包在部分架构上未导出的 termios 常量
+const (
+	tcflsh = 0x5407
+	cbaud  = 0x100F
+)
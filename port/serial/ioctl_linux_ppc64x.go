@@ -0,0 +1,9 @@
+//go:build linux && (ppc64 || ppc64le)
+
+package serial
+
+// syscall 包在部分架构上未导出的 termios 常量
+const (
+	tcflsh = 0x2000741F
+	cbaud  = 0xFF
+)
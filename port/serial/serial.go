@@ -0,0 +1,22 @@
+// Package serial 提供基于 termios 的串口实现，可直接作为 at.Port 使用
+package serial
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrHangup 设备已挂断，通常是 USB 模块被拔出或重新枚举
+	ErrHangup = errors.New("serial: hangup")
+	// ErrUnsupported 当前平台不支持
+	ErrUnsupported = errors.New("serial: unsupported platform")
+)
+
+// Config 串口配置，数据格式固定为 8N1
+type Config struct {
+	Name        string        // 设备路径，如 /dev/ttyUSB2
+	Baud        int           // 波特率，默认 115200
+	ReadTimeout time.Duration // 读取超时，为 0 时一直等待数据
+	FlowControl bool          // 启用 RTS/CTS 硬件流控
+}
@@ -0,0 +1,178 @@
+//go:build linux
+
+package serial
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// crtscts RTS/CTS 硬件流控标志，syscall 包未导出
+const crtscts = 0x80000000
+
+// 支持的波特率
+var bauds = map[int]uint32{
+	1200:    syscall.B1200,
+	2400:    syscall.B2400,
+	4800:    syscall.B4800,
+	9600:    syscall.B9600,
+	19200:   syscall.B19200,
+	38400:   syscall.B38400,
+	57600:   syscall.B57600,
+	115200:  syscall.B115200,
+	230400:  syscall.B230400,
+	460800:  syscall.B460800,
+	921600:  syscall.B921600,
+	1000000: syscall.B1000000,
+	1500000: syscall.B1500000,
+	2000000: syscall.B2000000,
+	3000000: syscall.B3000000,
+	4000000: syscall.B4000000,
+}
+
+// Port 串口连接
+type Port struct {
+	file    *os.File      // 以非阻塞方式打开，读写由运行时轮询，Close 可中断阻塞的 Read
+	timeout time.Duration // 读取超时
+}
+
+// Open 打开串口并设置为原始模式
+func Open(config *Config) (*Port, error) {
+	baud := config.Baud
+	if baud == 0 {
+		baud = 115200
+	}
+	speed, ok := bauds[baud]
+	if !ok {
+		return nil, fmt.Errorf("serial: unsupported baud rate %d", baud)
+	}
+
+	fd, err := syscall.Open(config.Name, syscall.O_RDWR|syscall.O_NOCTTY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: config.Name, Err: err}
+	}
+
+	p := &Port{
+		file:    os.NewFile(uintptr(fd), config.Name),
+		timeout: config.ReadTimeout,
+	}
+	if err := p.configure(speed, config.FlowControl); err != nil {
+		p.file.Close()
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// configure 设置波特率、8N1、流控并关闭行编辑、回显和字符转换
+func (p *Port) configure(speed uint32, flow bool) error {
+	var t syscall.Termios
+	if err := p.ioctl(syscall.TCGETS, unsafe.Pointer(&t)); err != nil {
+		return fmt.Errorf("serial: get termios: %w", err)
+	}
+
+	t.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP |
+		syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON | syscall.IXOFF | syscall.IXANY
+	t.Oflag &^= syscall.OPOST
+	t.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	t.Cflag &^= syscall.CSIZE | syscall.PARENB | syscall.CSTOPB | cbaud | crtscts
+	t.Cflag |= syscall.CS8 | syscall.CREAD | syscall.CLOCAL | speed
+	if flow {
+		t.Cflag |= crtscts
+	}
+
+	// 读取超时由运行时轮询实现，termios 只需保证有数据即返回
+	t.Cc[syscall.VMIN] = 1
+	t.Cc[syscall.VTIME] = 0
+
+	if err := p.ioctl(syscall.TCSETS, unsafe.Pointer(&t)); err != nil {
+		return fmt.Errorf("serial: set termios: %w", err)
+	}
+	return nil
+}
+
+// Read 读取数据，超时返回 Timeout() 为 true 的错误，设备挂断返回 ErrHangup
+func (p *Port) Read(buf []byte) (int, error) {
+	if p.timeout > 0 {
+		if err := p.file.SetReadDeadline(time.Now().Add(p.timeout)); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := p.file.Read(buf)
+	if err == io.EOF {
+		// 非阻塞模式下无数据会等待，读到 0 字节只可能是挂断
+		return n, ErrHangup
+	}
+	return n, err
+}
+
+// Write 写入数据
+func (p *Port) Write(data []byte) (int, error) {
+	return p.file.Write(data)
+}
+
+// Flush 丢弃尚未读取的输入和尚未发送的输出
+func (p *Port) Flush() error {
+	return p.control(func(fd uintptr) syscall.Errno {
+		_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, tcflsh, syscall.TCIOFLUSH)
+		return errno
+	})
+}
+
+// SetDTR 设置 DTR 信号，多数模块在 DTR 拉低时挂断数据连接
+func (p *Port) SetDTR(on bool) error {
+	return p.setModemBit(syscall.TIOCM_DTR, on)
+}
+
+// SetRTS 设置 RTS 信号，启用硬件流控时由驱动控制
+func (p *Port) SetRTS(on bool) error {
+	return p.setModemBit(syscall.TIOCM_RTS, on)
+}
+
+// Close 关闭串口，阻塞中的 Read 会立即返回
+func (p *Port) Close() error {
+	return p.file.Close()
+}
+
+// setModemBit 设置或清除调制解调器控制线
+func (p *Port) setModemBit(bit int, on bool) error {
+	req := uint(syscall.TIOCMBIC)
+	if on {
+		req = syscall.TIOCMBIS
+	}
+	v := int32(bit)
+	return p.ioctl(req, unsafe.Pointer(&v))
+}
+
+// ioctl 在串口文件描述符上执行参数为指针的 ioctl
+func (p *Port) ioctl(req uint, arg unsafe.Pointer) error {
+	return p.control(func(fd uintptr) syscall.Errno {
+		_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(req), uintptr(arg))
+		return errno
+	})
+}
+
+// control 在串口文件描述符上执行系统调用
+func (p *Port) control(fn func(fd uintptr) syscall.Errno) error {
+	raw, err := p.file.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var errno syscall.Errno
+	err = raw.Control(func(fd uintptr) {
+		errno = fn(fd)
+	})
+	if err != nil {
+		return err
+	}
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
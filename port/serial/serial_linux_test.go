@@ -0,0 +1,100 @@
+//go:build linux
+
+package serial
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// openPty 打开伪终端主端并返回从端路径，不支持伪终端时跳过测试
+func openPty(t *testing.T) (*os.File, string) {
+	t.Helper()
+
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("pty unavailable: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+
+	var unlock int32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, master.Fd(), syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); errno != 0 {
+		t.Skipf("unlockpt: %v", errno)
+	}
+	var index uint32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, master.Fd(), syscall.TIOCGPTN, uintptr(unsafe.Pointer(&index))); errno != 0 {
+		t.Skipf("ptsname: %v", errno)
+	}
+
+	name := fmt.Sprintf("/dev/pts/%d", index)
+	if _, err := os.Stat(name); err != nil {
+		t.Skipf("pty slave unavailable: %v", err)
+	}
+	return master, name
+}
+
+// isTimeout 判断是否为读取超时
+func isTimeout(err error) bool {
+	t, ok := err.(interface{ Timeout() bool })
+	return ok && t.Timeout()
+}
+
+func TestPty(t *testing.T) {
+	master, name := openPty(t)
+
+	p, err := Open(&Config{Name: name, ReadTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer p.Close()
+
+	buf := make([]byte, 64)
+
+	// 无数据时按超时返回
+	start := time.Now()
+	if n, err := p.Read(buf); n != 0 || !isTimeout(err) {
+		t.Fatalf("Read = %d, %v, want timeout", n, err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond || elapsed > time.Second {
+		t.Fatalf("Read returned after %v", elapsed)
+	}
+
+	// 原始模式下写入的 CR 不被转换
+	if _, err := p.Write([]byte("AT\r")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	n, err := master.Read(buf)
+	if err != nil || string(buf[:n]) != "AT\r" {
+		t.Fatalf("master Read = %q, %v", buf[:n], err)
+	}
+
+	if _, err := master.Write([]byte("\r\nOK\r\n")); err != nil {
+		t.Fatalf("master Write: %v", err)
+	}
+	n, err = p.Read(buf)
+	if err != nil || string(buf[:n]) != "\r\nOK\r\n" {
+		t.Fatalf("Read = %q, %v", buf[:n], err)
+	}
+
+	// Flush 丢弃尚未读取的输入
+	if _, err := master.Write([]byte("+CMTI: \"SM\",1\r\n")); err != nil {
+		t.Fatalf("master Write: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if err := p.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if n, err := p.Read(buf); n != 0 || !isTimeout(err) {
+		t.Fatalf("Read after Flush = %q, %v, want timeout", buf[:n], err)
+	}
+}
+
+func TestOpenUnsupportedBaud(t *testing.T) {
+	if _, err := Open(&Config{Name: "/dev/null", Baud: 12345}); err == nil {
+		t.Fatal("Open with baud 12345 succeeded")
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package serial
+
+// Port 串口连接
+type Port struct{}
+
+// Open 打开串口，当前平台不支持
+func Open(config *Config) (*Port, error) {
+	return nil, ErrUnsupported
+}
+
+func (p *Port) Read(buf []byte) (int, error)   { return 0, ErrUnsupported }
+func (p *Port) Write(data []byte) (int, error) { return 0, ErrUnsupported }
+func (p *Port) Flush() error                   { return ErrUnsupported }
+func (p *Port) SetDTR(on bool) error           { return ErrUnsupported }
+func (p *Port) SetRTS(on bool) error           { return ErrUnsupported }
+func (p *Port) Close() error                   { return ErrUnsupported }
@@ -0,0 +1,409 @@
+// Package sim 提供内存中的模块模拟器，实现 at.Port 接口，便于在没有硬件时测试
+//
+// 模拟器按规则表应答 AT 命令，支持回显、"> " 提示符与 Ctrl-Z 提交、
+// 定时注入通知、短信存储（AT+CMGL/AT+CMGR/AT+CMGD/AT+CMGS），
+// 以及不应答（超时）、乱码和串口失效等异常情况。
+package sim
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrClosed 模拟器已关闭
+	ErrClosed = errors.New("sim: closed")
+	// ErrDisconnected 模拟串口失效，由 Disconnect 触发
+	ErrDisconnected = errors.New("sim: disconnected")
+)
+
+// timeoutError 读取超时，Timeout() 为 true
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "sim: read timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// Config 模拟器配置
+type Config struct {
+	Echo        bool          // 初始是否回显命令，可由 ATE0/ATE1 修改
+	ReadTimeout time.Duration // Read 无数据时的超时，为 0 时一直等待
+	Delay       time.Duration // 默认响应延迟
+}
+
+// Rule 命令应答规则
+type Rule struct {
+	Pattern   string                                    // 匹配整条命令的正则表达式，不区分大小写
+	Responses []string                                  // 响应行，最后一行通常为最终响应，为空时不应答（模拟超时）
+	Func      func(cmd string, match []string) []string // 动态生成响应，优先于 Responses
+	Prompt    func(cmd, data string) []string           // 不为 nil 时先输出 "> "，收到 Ctrl-Z 后根据数据生成响应
+	Delay     time.Duration                             // 响应延迟，为 0 时使用 Config.Delay
+	Times     int                                       // 剩余生效次数，0 表示不限
+	re        *regexp.Regexp
+}
+
+// Modem 模块模拟器
+type Modem struct {
+	config  Config
+	out     chan []byte   // 待读取的输出
+	in      chan []byte   // 已写入、待处理的输入
+	done    chan struct{} // 关闭时关闭
+	once    sync.Once
+	mu      sync.Mutex // 保护以下状态
+	rest    []byte     // 上次未读完的输出
+	rules   []*Rule    // 用户规则，优先于内置规则
+	builtin []*Rule    // 内置规则
+	echo    bool
+	failure error    // 不为 nil 时读写均返回该错误
+	history []string // 收到的命令
+	store   *store   // 短信存储
+}
+
+// New 创建模拟器
+func New(config *Config) *Modem {
+	if config == nil {
+		config = &Config{}
+	}
+
+	s := &Modem{
+		config: *config,
+		out:    make(chan []byte, 1024),
+		in:     make(chan []byte, 64),
+		done:   make(chan struct{}),
+		echo:   config.Echo,
+		store:  newStore(),
+	}
+	s.builtin = s.builtinRules()
+
+	go s.run()
+
+	return s
+}
+
+// AddRule 添加应答规则，后添加的规则优先
+func (s *Modem) AddRule(rule Rule) {
+	rule.re = compile(rule.Pattern)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = append([]*Rule{&rule}, s.rules...)
+}
+
+// Handle 以固定响应应答匹配的命令，如 Handle(`AT\+CSQ`, "+CSQ: 20,99", "OK")
+func (s *Modem) Handle(pattern string, responses ...string) {
+	s.AddRule(Rule{Pattern: pattern, Responses: responses})
+}
+
+// HandleFunc 以动态响应应答匹配的命令，match 为正则表达式的子匹配
+func (s *Modem) HandleFunc(pattern string, fn func(cmd string, match []string) []string) {
+	s.AddRule(Rule{Pattern: pattern, Func: fn})
+}
+
+// Ignore 不应答匹配的命令，用于模拟超时
+func (s *Modem) Ignore(pattern string) {
+	s.AddRule(Rule{Pattern: pattern, Responses: []string{}})
+}
+
+// URC 立即输出通知行
+func (s *Modem) URC(lines ...string) {
+	s.emit(lines...)
+}
+
+// URCAfter 在 d 之后输出通知行
+func (s *Modem) URCAfter(d time.Duration, lines ...string) {
+	time.AfterFunc(d, func() { s.emit(lines...) })
+}
+
+// URCEvery 每隔 d 输出一次通知行，返回停止函数
+func (s *Modem) URCEvery(d time.Duration, lines ...string) func() {
+	ticker := time.NewTicker(d)
+	stop := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.emit(lines...)
+			case <-stop:
+				return
+			case <-s.done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(stop) }) }
+}
+
+// Inject 原样输出数据，不添加换行，可用于模拟乱码或不完整的行
+func (s *Modem) Inject(raw string) {
+	s.write([]byte(raw))
+}
+
+// Disconnect 模拟串口失效，之后读写均返回 ErrDisconnected
+func (s *Modem) Disconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failure = ErrDisconnected
+}
+
+// History 返回收到的命令（不含结束符），提示符后的数据以 "<data>" 形式记录
+func (s *Modem) History() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.history...)
+}
+
+// Read 读取模拟器输出，超时返回 Timeout() 为 true 的错误
+func (s *Modem) Read(buf []byte) (int, error) {
+	if err := s.err(); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	if len(s.rest) > 0 {
+		n := copy(buf, s.rest)
+		s.rest = s.rest[n:]
+		s.mu.Unlock()
+		return n, nil
+	}
+	s.mu.Unlock()
+
+	var expired <-chan time.Time
+	if s.config.ReadTimeout > 0 {
+		timer := time.NewTimer(s.config.ReadTimeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case data := <-s.out:
+		n := copy(buf, data)
+		s.mu.Lock()
+		s.rest = data[n:]
+		s.mu.Unlock()
+		return n, nil
+	case <-expired:
+		return 0, timeoutError{}
+	case <-s.done:
+		return 0, ErrClosed
+	}
+}
+
+// Write 向模拟器写入命令或提示符后的数据
+func (s *Modem) Write(data []byte) (int, error) {
+	if err := s.err(); err != nil {
+		return 0, err
+	}
+
+	select {
+	case s.in <- append([]byte{}, data...):
+		return len(data), nil
+	case <-s.done:
+		return 0, ErrClosed
+	}
+}
+
+// Flush 丢弃尚未读取的输出
+func (s *Modem) Flush() error {
+	s.mu.Lock()
+	s.rest = nil
+	s.mu.Unlock()
+	for {
+		select {
+		case <-s.out:
+		default:
+			return nil
+		}
+	}
+}
+
+// Close 关闭模拟器
+func (s *Modem) Close() error {
+	s.once.Do(func() { close(s.done) })
+	return nil
+}
+
+// err 返回关闭或失效错误
+func (s *Modem) err() error {
+	select {
+	case <-s.done:
+		return ErrClosed
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failure
+}
+
+// run 按顺序处理输入：命令以 CR 结束，提示符后的数据以 Ctrl-Z 提交、ESC 取消
+func (s *Modem) run() {
+	line := []byte{}
+	var prompt *Rule
+	var promptCmd string
+
+	for {
+		var data []byte
+		select {
+		case data = <-s.in:
+		case <-s.done:
+			return
+		}
+
+		for _, b := range data {
+			if prompt != nil {
+				switch b {
+				case 0x1A:
+					// 去掉命令结束符中紧随 CR 的 LF
+					data := strings.TrimSpace(string(line))
+					s.record("<" + data + ">")
+					s.reply(prompt, prompt.Prompt(promptCmd, data))
+					prompt, line = nil, line[:0]
+				case 0x1B:
+					s.record("<ESC>")
+					prompt, line = nil, line[:0]
+				default:
+					line = append(line, b)
+				}
+				continue
+			}
+
+			switch b {
+			case '\r':
+				cmd := strings.TrimSpace(string(line))
+				line = line[:0]
+				if cmd == "" {
+					continue
+				}
+				s.record(cmd)
+				if s.echoing() {
+					s.write([]byte(cmd + "\r"))
+				}
+				prompt, promptCmd = s.execute(cmd)
+			case '\n':
+				// 命令结束符为 CR，忽略 LF
+			default:
+				line = append(line, b)
+			}
+		}
+	}
+}
+
+// execute 按规则应答命令，规则需要提示符时返回该规则
+func (s *Modem) execute(cmd string) (*Rule, string) {
+	rule, match := s.match(cmd)
+	if rule == nil {
+		s.reply(&Rule{}, []string{"ERROR"})
+		return nil, ""
+	}
+
+	if rule.Prompt != nil {
+		s.sleep(rule.Delay)
+		s.write([]byte("\r\n> "))
+		return rule, cmd
+	}
+
+	responses := rule.Responses
+	if rule.Func != nil {
+		responses = rule.Func(cmd, match)
+	}
+	s.reply(rule, responses)
+	return nil, ""
+}
+
+// match 查找第一条匹配的规则，用户规则优先于内置规则
+func (s *Modem) match(cmd string) (*Rule, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, rules := range [][]*Rule{s.rules, s.builtin} {
+		for _, rule := range rules {
+			match := rule.re.FindStringSubmatch(cmd)
+			if match == nil {
+				continue
+			}
+			if rule.Times > 0 {
+				rule.Times--
+				if rule.Times == 0 {
+					s.rules = remove(s.rules, rule)
+				}
+			}
+			return rule, match
+		}
+	}
+	return nil, nil
+}
+
+// reply 按规则的延迟输出响应
+func (s *Modem) reply(rule *Rule, responses []string) {
+	if len(responses) == 0 {
+		return
+	}
+	s.sleep(rule.Delay)
+	s.emit(responses...)
+}
+
+// sleep 响应前等待，模拟器关闭时立即返回
+func (s *Modem) sleep(d time.Duration) {
+	if d == 0 {
+		d = s.config.Delay
+	}
+	if d <= 0 {
+		return
+	}
+
+	select {
+	case <-time.After(d):
+	case <-s.done:
+	}
+}
+
+// emit 输出以 CRLF 包围的响应或通知行
+func (s *Modem) emit(lines ...string) {
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString("\r\n" + line + "\r\n")
+	}
+	s.write([]byte(b.String()))
+}
+
+// write 将数据放入输出队列
+func (s *Modem) write(data []byte) {
+	select {
+	case s.out <- data:
+	case <-s.done:
+	}
+}
+
+// echoing 返回当前是否回显
+func (s *Modem) echoing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.echo
+}
+
+// record 记录收到的命令
+func (s *Modem) record(cmd string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history = append(s.history, cmd)
+}
+
+// compile 编译匹配整条命令的正则表达式
+func compile(pattern string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(?:` + pattern + `)$`)
+}
+
+// remove 从规则列表中删除规则
+func remove(rules []*Rule, rule *Rule) []*Rule {
+	for i, r := range rules {
+		if r == rule {
+			return append(rules[:i:i], rules[i+1:]...)
+		}
+	}
+	return rules
+}
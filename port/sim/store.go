@@ -0,0 +1,185 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// PDU 模式短信状态
+const (
+	StatUnread = 0 // 已接收未读
+	StatRead   = 1 // 已接收已读
+	StatUnsent = 2 // 已存储未发送
+	StatSent   = 3 // 已存储已发送
+	StatAll    = 4 // 全部（仅用于 AT+CMGL）
+)
+
+// message 存储的短信
+type message struct {
+	stat int
+	pdu  string
+}
+
+// store 短信存储，调用方需持有 Modem.mu
+type store struct {
+	messages map[int]*message // 按存储索引
+	mr       int              // 下一个消息参考号
+	sent     []string         // 通过 AT+CMGS 提交的 PDU
+}
+
+func newStore() *store {
+	return &store{messages: map[int]*message{}}
+}
+
+// add 存入短信，返回从 1 开始的最小空闲索引
+func (st *store) add(stat int, pdu string) int {
+	index := 1
+	for st.messages[index] != nil {
+		index++
+	}
+	st.messages[index] = &message{stat: stat, pdu: pdu}
+	return index
+}
+
+// indices 返回按顺序排列的存储索引
+func (st *store) indices() []int {
+	indices := make([]int, 0, len(st.messages))
+	for index := range st.messages {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// StoreSMS 向短信存储写入一条带 SMSC 地址的十六进制 PDU，返回存储索引
+func (s *Modem) StoreSMS(stat int, pdu string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store.add(stat, pdu)
+}
+
+// DeliverSMS 模拟收到短信：存为未读并输出 +CMTI 通知，返回存储索引
+func (s *Modem) DeliverSMS(pdu string) int {
+	index := s.StoreSMS(StatUnread, pdu)
+	s.emit(fmt.Sprintf(`+CMTI: "SM",%d`, index))
+	return index
+}
+
+// SentSMS 返回通过 AT+CMGS 提交的 PDU
+func (s *Modem) SentSMS() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.store.sent...)
+}
+
+// builtinRules 内置规则：基本命令、回显开关和 PDU 模式短信存储
+func (s *Modem) builtinRules() []*Rule {
+	rules := []*Rule{
+		{Pattern: `AT`, Responses: []string{"OK"}},
+		{Pattern: `ATE([01])`, Func: s.handleEcho},
+		{Pattern: `AT\+CMGF=[01]`, Responses: []string{"OK"}},
+		{Pattern: `AT\+CMGL=(\d)`, Func: s.handleList},
+		{Pattern: `AT\+CMGR=(\d+)`, Func: s.handleRead},
+		{Pattern: `AT\+CMGD=(\d+)(?:,(\d))?`, Func: s.handleDelete},
+		{Pattern: `AT\+CMGS=(\d+)`, Prompt: s.handleSend},
+	}
+	for _, rule := range rules {
+		rule.re = compile(rule.Pattern)
+	}
+	return rules
+}
+
+// handleEcho ATE0 / ATE1
+func (s *Modem) handleEcho(cmd string, match []string) []string {
+	s.mu.Lock()
+	s.echo = match[1] == "1"
+	s.mu.Unlock()
+	return []string{"OK"}
+}
+
+// handleList AT+CMGL=<stat>，读取未读短信后将其标记为已读
+func (s *Modem) handleList(cmd string, match []string) []string {
+	stat, _ := strconv.Atoi(match[1])
+	if stat > StatAll {
+		return []string{"+CMS ERROR: 302"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lines := []string{}
+	for _, index := range s.store.indices() {
+		msg := s.store.messages[index]
+		if stat != StatAll && msg.stat != stat {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("+CMGL: %d,%d,,%d", index, msg.stat, tpduLength(msg.pdu)), msg.pdu)
+		if msg.stat == StatUnread {
+			msg.stat = StatRead
+		}
+	}
+	return append(lines, "OK")
+}
+
+// handleRead AT+CMGR=<index>
+func (s *Modem) handleRead(cmd string, match []string) []string {
+	index, _ := strconv.Atoi(match[1])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := s.store.messages[index]
+	if msg == nil {
+		return []string{"+CMS ERROR: 321"} // invalid memory index
+	}
+	lines := []string{fmt.Sprintf("+CMGR: %d,,%d", msg.stat, tpduLength(msg.pdu)), msg.pdu, "OK"}
+	if msg.stat == StatUnread {
+		msg.stat = StatRead
+	}
+	return lines
+}
+
+// handleDelete AT+CMGD=<index>[,<delflag>]
+func (s *Modem) handleDelete(cmd string, match []string) []string {
+	index, _ := strconv.Atoi(match[1])
+	flag, _ := strconv.Atoi(match[2])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, msg := range s.store.messages {
+		switch {
+		case flag == 0 && i == index,
+			flag == 1 && msg.stat == StatRead,
+			flag == 2 && (msg.stat == StatRead || msg.stat == StatSent),
+			flag == 3 && msg.stat != StatUnread,
+			flag == 4:
+			delete(s.store.messages, i)
+		}
+	}
+	return []string{"OK"}
+}
+
+// handleSend AT+CMGS=<length> 提示符后提交的 PDU
+func (s *Modem) handleSend(cmd, data string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store.sent = append(s.store.sent, data)
+	mr := s.store.mr
+	s.store.mr = (s.store.mr + 1) % 256
+	return []string{fmt.Sprintf("+CMGS: %d", mr), "OK"}
+}
+
+// tpduLength 根据首字节的 SMSC 地址长度计算 TPDU 字节数
+func tpduLength(pdu string) int {
+	if len(pdu) < 2 {
+		return 0
+	}
+	smsc, err := strconv.ParseUint(pdu[:2], 16, 8)
+	if err != nil {
+		return 0
+	}
+	return len(pdu)/2 - 1 - int(smsc)
+}
@@ -0,0 +1,71 @@
+// Package tcp 提供基于 TCP 连接的 at.Port 实现，适用于 ser2net 原始模式等串口服务器
+package tcp
+
+import (
+	"errors"
+	"io"
+	"net"
+	"time"
+)
+
+// ErrClosed 对端关闭了连接
+var ErrClosed = errors.New("tcp: connection closed by peer")
+
+// Config 连接配置
+type Config struct {
+	Address     string        // 服务器地址，如 192.168.1.10:2000
+	DialTimeout time.Duration // 连接超时，默认 10 秒
+	ReadTimeout time.Duration // 读取超时，为 0 时一直等待数据
+}
+
+// Port TCP 连接
+type Port struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+// Dial 连接串口服务器
+func Dial(config *Config) (*Port, error) {
+	timeout := config.DialTimeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+
+	conn, err := net.DialTimeout("tcp", config.Address, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Port{conn: conn, timeout: config.ReadTimeout}, nil
+}
+
+// Read 读取数据，超时返回 Timeout() 为 true 的错误，对端关闭时返回 ErrClosed
+func (p *Port) Read(buf []byte) (int, error) {
+	if p.timeout > 0 {
+		if err := p.conn.SetReadDeadline(time.Now().Add(p.timeout)); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := p.conn.Read(buf)
+	if err == io.EOF {
+		// 对端关闭，返回包内错误便于调用方识别
+		return n, ErrClosed
+	}
+	return n, err
+}
+
+// Write 写入数据
+func (p *Port) Write(data []byte) (int, error) {
+	return p.conn.Write(data)
+}
+
+// Flush 原始 TCP 连接无法清空远端缓冲区，直接返回
+func (p *Port) Flush() error {
+	return nil
+}
+
+// Close 关闭连接
+func (p *Port) Close() error {
+	return p.conn.Close()
+}
@@ -0,0 +1,80 @@
+package tcp
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// listen 监听本地端口，返回地址和已接受连接的通道
+func listen(t *testing.T) (string, <-chan net.Conn) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	conns := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		conns <- conn
+	}()
+	return ln.Addr().String(), conns
+}
+
+// isTimeout 判断是否为读取超时
+func isTimeout(err error) bool {
+	t, ok := err.(interface{ Timeout() bool })
+	return ok && t.Timeout()
+}
+
+func TestReadWrite(t *testing.T) {
+	addr, conns := listen(t)
+	p, err := Dial(&Config{Address: addr, ReadTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer p.Close()
+
+	var conn net.Conn
+	select {
+	case conn = <-conns:
+	case <-time.After(time.Second):
+		t.Fatal("no connection")
+	}
+	defer conn.Close()
+
+	// 数据原样透传，0xFF 等字节不做转义
+	if _, err := p.Write([]byte("AT\r\xff")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	if err != nil || string(buf[:n]) != "AT\r\xff" {
+		t.Fatalf("server Read = %q, %v", buf[:n], err)
+	}
+
+	conn.Write([]byte("\r\nOK\r\n"))
+	n, err = p.Read(buf)
+	if err != nil || string(buf[:n]) != "\r\nOK\r\n" {
+		t.Fatalf("Read = %q, %v", buf[:n], err)
+	}
+
+	// 无数据时按超时返回
+	if n, err := p.Read(buf); n != 0 || !isTimeout(err) {
+		t.Fatalf("Read = %d, %v, want timeout", n, err)
+	}
+
+	// 对端关闭时返回 ErrClosed
+	conn.Close()
+	if _, err := p.Read(buf); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Read after close = %v, want ErrClosed", err)
+	}
+}